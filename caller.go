@@ -0,0 +1,123 @@
+package serr
+
+import (
+	"runtime"
+	"sync"
+)
+
+// callerLocation and callerFunction hold the program counter of a wrap in place of
+// its "location" and "function" strings. They resolve to those strings when printed,
+// so an error that is handled without being rendered never pays for symbolization
+type callerLocation uintptr
+type callerFunction uintptr
+
+func (pc callerLocation) String() string {
+	return callerFrame(uintptr(pc)).location
+}
+
+func (pc callerFunction) String() string {
+	return callerFrame(uintptr(pc)).function
+}
+
+// MarshalText renders the location for encoders that don't call String
+func (pc callerLocation) MarshalText() ([]byte, error) {
+	return []byte(pc.String()), nil
+}
+
+// MarshalText renders the function name for encoders that don't call String
+func (pc callerFunction) MarshalText() ([]byte, error) {
+	return []byte(pc.String()), nil
+}
+
+// resolvedFrame is the rendered caller context of a program counter,
+// under the location config it was rendered with
+type resolvedFrame struct {
+	frame              runtime.Frame
+	cfg                *locationConfig
+	fields             CallerFields
+	location, function string
+}
+
+// frameCache maps program counters to their resolvedFrame.
+// Errors are typically created at a limited set of call sites, so the cache stays small
+var frameCache sync.Map
+
+// callerFrame resolves a program counter recorded by AppendCallerContext.
+// Symbolization happens once per program counter; changing the location format only re-renders
+func callerFrame(pc uintptr) *resolvedFrame {
+	cfg := currentLocationConfig()
+
+	var frame runtime.Frame
+	if cached, ok := frameCache.Load(pc); ok {
+		rf := cached.(*resolvedFrame)
+		if rf.cfg == cfg {
+			return rf
+		}
+		frame = rf.frame
+	} else {
+		frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	}
+
+	format := cfg.formatFor(frame.Function)
+	rf := &resolvedFrame{
+		frame:    frame,
+		cfg:      cfg,
+		fields:   format.Fields,
+		location: format.location(frame),
+		function: format.function(frame),
+	}
+
+	frameCache.Store(pc, rf)
+	return rf
+}
+
+// callerFieldsFor returns which caller attributes to record for a program counter.
+// The frame is only resolved when per-package formats are configured
+func callerFieldsFor(pc uintptr) CallerFields {
+	cfg := currentLocationConfig()
+	if len(cfg.packages) == 0 {
+		return cfg.global.Fields
+	}
+	return callerFrame(pc).fields
+}
+
+// resolveCaller turns recorded program counters into their strings,
+// so callers of FieldsMapOfAny and the like keep seeing string locations and function names
+func resolveCaller(val any) any {
+	switch v := val.(type) {
+	case callerLocation:
+		return v.String()
+	case callerFunction:
+		return v.String()
+	}
+	return val
+}
+
+// Frames returns the caller frames recorded by each New and Wrap of the SErr, innermost first.
+// Since a frame is captured per wrap rather than per call, they trace the error's path
+// through the layers of an application more than a full stack trace would
+func (se SErr) Frames() (frames []runtime.Frame) {
+	var last uintptr
+	for i := 1; i < len(se.fields); i += 2 {
+		var pc uintptr
+		switch v := se.fields[i].(type) {
+		case callerLocation:
+			pc = uintptr(v)
+		case callerFunction:
+			pc = uintptr(v)
+			if pc == last && i >= 2 && isCallerLocation(se.fields[i-2]) {
+				continue // the function of the location just added
+			}
+		default:
+			continue
+		}
+		last = pc
+		frames = append(frames, callerFrame(pc).frame)
+	}
+	return
+}
+
+func isCallerLocation(val any) bool {
+	_, ok := val.(callerLocation)
+	return ok
+}
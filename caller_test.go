@@ -0,0 +1,57 @@
+package serr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCallerContextIsLazy(t *testing.T) {
+	err := New("lazy")
+	ser := err.(SErr)
+
+	// Only program counters are stored until the error is rendered
+	if _, ok := ser.fields[len(ser.fields)-3].(callerLocation); !ok {
+		t.Errorf("Expected location to be stored as a program counter, got %T", ser.fields[len(ser.fields)-3])
+	}
+
+	loc, ok := ser.GetAttribute("location")
+	if !ok || !strings.HasPrefix(loc.(string), "serr/caller_test.go:") {
+		t.Errorf("Expected location to resolve to this file, got '%v'", loc)
+	}
+	if fn := ser.FieldsMap()["function"]; fn != "rohanthewiz/serr.TestCallerContextIsLazy" {
+		t.Errorf("Expected function to resolve to this test, got '%s'", fn)
+	}
+
+	byts, jerr := json.Marshal(ser.fields)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	if !strings.Contains(string(byts), `"rohanthewiz/serr.TestCallerContextIsLazy"`) {
+		t.Errorf("Expected JSON encoding to resolve the function name, got %s", byts)
+	}
+}
+
+func TestCallerFrameIsCached(t *testing.T) {
+	pc := uintptr(New("cached").(SErr).fields[1].(callerLocation))
+	if callerFrame(pc) != callerFrame(pc) {
+		t.Error("Expected a program counter to be resolved once")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	inner := New("inner")
+	err := Wrap(inner, "layer", "outer")
+	frames := err.(SErr).Frames()
+	if len(frames) != 2 {
+		t.Fatalf("Expected a frame per wrap, got %d", len(frames))
+	}
+	for _, frame := range frames {
+		if frame.Function != "github.com/rohanthewiz/serr.TestFrames" || !strings.HasSuffix(frame.File, "caller_test.go") {
+			t.Errorf("Unexpected frame %+v", frame)
+		}
+	}
+	if frames[0].Line+1 != frames[1].Line {
+		t.Errorf("Expected the innermost frame first, got lines %d and %d", frames[0].Line, frames[1].Line)
+	}
+}
@@ -0,0 +1,196 @@
+// Package catalog reads catalogs of error definitions.
+// A catalog is a reviewed list of an application's errors: their codes, messages,
+// user messages, severities and HTTP statuses. It is the input of cmd/serrgen.
+//
+// Catalogs are JSON, which keeps serr free of dependencies:
+//
+//	{
+//	  "package": "apperrs",
+//	  "errors": [
+//	    {
+//	      "code": "user_not_found",
+//	      "message": "user {id} not found",
+//	      "kind": "not_found",
+//	      "params": {"id": "int"},
+//	      "user_msg": "We could not find that user",
+//	      "severity": "warn",
+//	      "http_status": 404,
+//	      "description": "The user id does not exist or was deleted"
+//	    }
+//	  ]
+//	}
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// Catalog is a set of error definitions destined for one Go package
+type Catalog struct {
+	Package string  `json:"package"`
+	Errors  []Entry `json:"errors"`
+}
+
+// Entry defines a single error
+type Entry struct {
+	// Code uniquely identifies the error, e.g. "user_not_found"
+	Code string `json:"code"`
+	// Message is the internal error message, in serr's named placeholder syntax
+	Message string `json:"message"`
+	// Kind is one of the serr.Kind values, e.g. "not_found"
+	Kind string `json:"kind,omitempty"`
+	// Params optionally gives the Go type of message placeholders. The default is any
+	Params map[string]string `json:"params,omitempty"`
+	// UserMsg is the message shown to users. It may reference the message placeholders
+	UserMsg string `json:"user_msg,omitempty"`
+	// Severity of the user message, e.g. "warn"
+	Severity string `json:"severity,omitempty"`
+	// HTTPStatus overrides the status implied by Kind
+	HTTPStatus  int    `json:"http_status,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var kinds = []serr.Kind{serr.KindUnknown, serr.KindInvalid, serr.KindNotFound, serr.KindConflict,
+	serr.KindUnauthorized, serr.KindForbidden, serr.KindTimeout, serr.KindUnavailable, serr.KindInternal}
+
+// Load reads and validates a catalog file
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, serr.Wrap(err, "path", path)
+	}
+
+	cat, err := Parse(data)
+	if err != nil {
+		return nil, serr.Wrap(err, "path", path)
+	}
+	return cat, nil
+}
+
+// Parse decodes and validates a catalog
+func Parse(data []byte) (*Catalog, error) {
+	cat := &Catalog{}
+	if err := json.Unmarshal(data, cat); err != nil {
+		return nil, serr.Wrap(err, "unable to decode catalog")
+	}
+	if err := cat.Validate(); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// Validate checks that codes are unique and entries are well-formed
+func (cat *Catalog) Validate() error {
+	seen := map[string]bool{}
+
+	for i, entry := range cat.Errors {
+		index := fmt.Sprint(i)
+		if entry.Code == "" {
+			return serr.New("catalog entry has no code", "index", index)
+		}
+		if seen[entry.Code] {
+			return serr.New("duplicate error code in catalog", "code", entry.Code)
+		}
+		seen[entry.Code] = true
+
+		if entry.Message == "" {
+			return serr.New("catalog entry has no message", "code", entry.Code)
+		}
+		if !validKind(entry.Kind) {
+			return serr.New("catalog entry has an unknown kind", "code", entry.Code, "kind", entry.Kind)
+		}
+		for name := range entry.Params {
+			if !contains(entry.ParamNames(), name) {
+				return serr.New("catalog param is not a message placeholder", "code", entry.Code, "param", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Lookup returns the entry with the given code
+func (cat *Catalog) Lookup(code string) (Entry, bool) {
+	for _, entry := range cat.Errors {
+		if entry.Code == code {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// ParamNames returns the distinct placeholder names of the message, in order
+func (e Entry) ParamNames() (names []string) {
+	for _, name := range serr.PlaceholderNames(e.Message) {
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// ParamType returns the Go type of a message placeholder
+func (e Entry) ParamType(name string) string {
+	if typ, ok := e.Params[name]; ok && typ != "" {
+		return typ
+	}
+	return "any"
+}
+
+// HTTPStatusOrDefault returns the entry's HTTP status, or the status implied by its kind
+func (e Entry) HTTPStatusOrDefault() int {
+	if e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	return serr.Kind(e.Kind).HTTPStatus()
+}
+
+// GoName converts the error code into an exported Go identifier, e.g. user_not_found => UserNotFound
+func (e Entry) GoName() string {
+	return GoIdentifier(e.Code, true)
+}
+
+// GoIdentifier converts a snake, kebab or dotted name into a Go identifier
+func GoIdentifier(name string, exported bool) string {
+	var sb strings.Builder
+	upper := exported
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = sb.Len() > 0 || exported
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteByte('E')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func validKind(kind string) bool {
+	for _, k := range kinds {
+		if string(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cat, err := Parse([]byte(`{
+		"package": "apperrs",
+		"errors": [
+			{"code": "user_not_found", "message": "user {id} not found in {org} (id {id})", "kind": "not_found", "params": {"id": "int"}},
+			{"code": "payment_declined", "message": "declined", "http_status": 402}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok := cat.Lookup("user_not_found")
+	if !ok {
+		t.Fatal("Expected to find user_not_found")
+	}
+	if names := strings.Join(entry.ParamNames(), ","); names != "id,org" {
+		t.Errorf("Expected distinct param names 'id,org', got '%s'", names)
+	}
+	if entry.ParamType("id") != "int" || entry.ParamType("org") != "any" {
+		t.Errorf("Unexpected param types %s, %s", entry.ParamType("id"), entry.ParamType("org"))
+	}
+	if entry.HTTPStatusOrDefault() != 404 {
+		t.Errorf("Expected HTTP status 404 from kind, got %d", entry.HTTPStatusOrDefault())
+	}
+	if entry.GoName() != "UserNotFound" {
+		t.Errorf("Expected Go name 'UserNotFound', got '%s'", entry.GoName())
+	}
+
+	entry, _ = cat.Lookup("payment_declined")
+	if entry.HTTPStatusOrDefault() != 402 {
+		t.Errorf("Expected explicit HTTP status 402, got %d", entry.HTTPStatusOrDefault())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog string
+		wantErr string
+	}{
+		{
+			name:    "Duplicate code",
+			catalog: `{"errors": [{"code": "a", "message": "m"}, {"code": "a", "message": "m"}]}`,
+			wantErr: "duplicate error code in catalog",
+		},
+		{
+			name:    "Unknown kind",
+			catalog: `{"errors": [{"code": "a", "message": "m", "kind": "oops"}]}`,
+			wantErr: "catalog entry has an unknown kind",
+		},
+		{
+			name:    "Param without placeholder",
+			catalog: `{"errors": [{"code": "a", "message": "m", "params": {"id": "int"}}]}`,
+			wantErr: "catalog param is not a message placeholder",
+		},
+		{
+			name:    "Missing message",
+			catalog: `{"errors": [{"code": "a"}]}`,
+			wantErr: "catalog entry has no message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.catalog))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error '%s', got '%v'", tt.wantErr, err)
+			}
+		})
+	}
+}
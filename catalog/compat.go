@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeType classifies a difference between two catalog versions
+type ChangeType string
+
+const (
+	CodeRemoved        ChangeType = "code_removed"
+	CodeAdded          ChangeType = "code_added"
+	KindChanged        ChangeType = "kind_changed"
+	HTTPStatusChanged  ChangeType = "http_status_changed"
+	SeverityChanged    ChangeType = "severity_changed"
+	ParamsChanged      ChangeType = "params_changed"
+	ParamTypeChanged   ChangeType = "param_type_changed"
+	MessageChanged     ChangeType = "message_changed"
+	UserMsgChanged     ChangeType = "user_msg_changed"
+	DescriptionChanged ChangeType = "description_changed"
+)
+
+// Change is a single difference between two catalog versions
+type Change struct {
+	Type     ChangeType
+	Code     string
+	Old, New string
+	// Breaking is true when clients or callers depending on the old catalog may break
+	Breaking bool
+}
+
+func (c Change) String() string {
+	level := "info"
+	if c.Breaking {
+		level = "BREAKING"
+	}
+	if c.Old == "" && c.New == "" {
+		return fmt.Sprintf("%s: %s %s", level, c.Code, c.Type)
+	}
+	return fmt.Sprintf("%s: %s %s: %q => %q", level, c.Code, c.Type, c.Old, c.New)
+}
+
+// Compare reports the differences from the old to the new catalog, ordered by code.
+// Removed codes and changes to what clients observe (kind, HTTP status, severity)
+// or to what callers pass (parameter names, order and types) are breaking.
+// Added codes and wording changes are not
+func Compare(old, new *Catalog) (changes []Change) {
+	for _, o := range old.Errors {
+		n, ok := new.Lookup(o.Code)
+		if !ok {
+			changes = append(changes, Change{Type: CodeRemoved, Code: o.Code, Breaking: true})
+			continue
+		}
+		changes = append(changes, compareEntries(o, n)...)
+	}
+
+	for _, n := range new.Errors {
+		if _, ok := old.Lookup(n.Code); !ok {
+			changes = append(changes, Change{Type: CodeAdded, Code: n.Code})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Code < changes[j].Code })
+	return
+}
+
+// Breaking filters changes down to the breaking ones
+func Breaking(changes []Change) (breaking []Change) {
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return
+}
+
+func compareEntries(o, n Entry) (changes []Change) {
+	add := func(typ ChangeType, old, new string, breaking bool) {
+		if old != new {
+			changes = append(changes, Change{Type: typ, Code: o.Code, Old: old, New: new, Breaking: breaking})
+		}
+	}
+
+	add(KindChanged, o.Kind, n.Kind, true)
+	add(HTTPStatusChanged, fmt.Sprint(o.HTTPStatusOrDefault()), fmt.Sprint(n.HTTPStatusOrDefault()), true)
+	add(SeverityChanged, o.Severity, n.Severity, true)
+
+	oldParams, newParams := o.ParamNames(), n.ParamNames()
+	add(ParamsChanged, strings.Join(oldParams, ", "), strings.Join(newParams, ", "), true)
+	for _, name := range oldParams {
+		if contains(newParams, name) {
+			add(ParamTypeChanged, name+" "+o.ParamType(name), name+" "+n.ParamType(name), true)
+		}
+	}
+
+	add(MessageChanged, o.Message, n.Message, false)
+	add(UserMsgChanged, o.UserMsg, n.UserMsg, false)
+	add(DescriptionChanged, o.Description, n.Description, false)
+	return
+}
@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old, err := Parse([]byte(`{"errors": [
+		{"code": "user_not_found", "message": "user {id} not found", "kind": "not_found", "severity": "warn"},
+		{"code": "payment_declined", "message": "payment {amount} declined", "params": {"amount": "float64"}},
+		{"code": "maintenance", "message": "down for maintenance", "kind": "unavailable"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := Parse([]byte(`{"errors": [
+		{"code": "user_not_found", "message": "user {userId} not found", "kind": "not_found", "severity": "error", "http_status": 410},
+		{"code": "payment_declined", "message": "payment of {amount} was declined", "params": {"amount": "int"}},
+		{"code": "rate_limited", "message": "too many requests"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Change{
+		{Type: CodeRemoved, Code: "maintenance", Breaking: true},
+		{Type: ParamTypeChanged, Code: "payment_declined", Old: "amount float64", New: "amount int", Breaking: true},
+		{Type: MessageChanged, Code: "payment_declined", Old: "payment {amount} declined", New: "payment of {amount} was declined"},
+		{Type: CodeAdded, Code: "rate_limited"},
+		{Type: HTTPStatusChanged, Code: "user_not_found", Old: "404", New: "410", Breaking: true},
+		{Type: SeverityChanged, Code: "user_not_found", Old: "warn", New: "error", Breaking: true},
+		{Type: ParamsChanged, Code: "user_not_found", Old: "id", New: "userId", Breaking: true},
+		{Type: MessageChanged, Code: "user_not_found", Old: "user {id} not found", New: "user {userId} not found"},
+	}
+
+	changes := Compare(old, new)
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Change %d: expected %v, got %v", i, expected[i], changes[i])
+		}
+	}
+
+	if n := len(Breaking(changes)); n != 5 {
+		t.Errorf("Expected 5 breaking changes, got %d", n)
+	}
+	if len(Compare(old, old)) != 0 {
+		t.Error("Expected no changes comparing a catalog to itself")
+	}
+}
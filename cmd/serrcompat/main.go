@@ -0,0 +1,63 @@
+// Command serrcompat compares two versions of an error catalog and reports breaking changes.
+//
+// Breaking changes are removed codes and changes to the kind, HTTP status, severity
+// or parameters of an existing code. serrcompat exits with status 1 when there are any,
+// so it can gate CI on changes to a catalog:
+//
+//	git show main:errors.json > /tmp/errors.old.json
+//	serrcompat /tmp/errors.old.json errors.json
+//
+// Use -all to also list non-breaking changes such as added codes or reworded messages.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/rohanthewiz/serr/catalog"
+)
+
+func main() {
+	all := flag.Bool("all", false, "also report non-breaking changes")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: serrcompat [-all] old.json new.json")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	breaking, err := run(flag.Arg(0), flag.Arg(1), *all)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "serrcompat:", serr.StringFromErr(err))
+		os.Exit(2)
+	}
+	if breaking {
+		os.Exit(1)
+	}
+}
+
+// run prints the changes between two catalog files and reports whether any are breaking
+func run(oldPath, newPath string, all bool) (breaking bool, err error) {
+	oldCat, err := catalog.Load(oldPath)
+	if err != nil {
+		return false, err
+	}
+	newCat, err := catalog.Load(newPath)
+	if err != nil {
+		return false, err
+	}
+
+	changes := catalog.Compare(oldCat, newCat)
+	for _, change := range changes {
+		if all || change.Breaking {
+			fmt.Println(change)
+		}
+	}
+	return len(catalog.Breaking(changes)) > 0, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/rohanthewiz/serr/catalog"
+)
+
+var funcs = template.FuncMap{
+	"quote":  strconv.Quote,
+	"params": goParams,
+	"args":   goArgs,
+	"kind":   kindExpr,
+	"cell":   mdCell,
+	"comment": func(s string) string {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n// ")
+	},
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(funcs).Parse(`// Code generated by serrgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Catalog.Package}}
+
+import (
+	"github.com/rohanthewiz/serr"
+)
+
+// Error definitions
+var (
+{{- range .Catalog.Errors}}
+	// Err{{.GoName}} - {{.Code}}{{if .Description}}
+	// {{comment .Description}}{{end}}
+	Err{{.GoName}} = serr.Define({{quote .Code}}, {{quote .Message}}
+		{{- with kind .Kind}}, {{.}}{{end}}
+		{{- if .UserMsg}}, serr.WithUserMsg({{quote .UserMsg}}, {{quote .Severity}}){{end}}
+		{{- if .HTTPStatus}}, serr.WithHTTPStatus({{.HTTPStatus}}){{end}})
+{{- end}}
+)
+
+// Catalog maps error codes to their definitions
+var Catalog = map[string]*serr.Definition{
+{{- range .Catalog.Errors}}
+	{{quote .Code}}: Err{{.GoName}},
+{{- end}}
+}
+
+// Lookup returns the definition of an error code
+func Lookup(code string) (def *serr.Definition, ok bool) {
+	def, ok = Catalog[code]
+	return
+}
+{{range .Catalog.Errors}}
+// New{{.GoName}} returns a new {{.Code}} error
+func New{{.GoName}}({{params .}}) error {
+	return Err{{.GoName}}.NewDepth(1{{args .}})
+}
+
+// Wrap{{.GoName}} wraps err as a {{.Code}} error
+func Wrap{{.GoName}}(err error{{with params .}}, {{.}}{{end}}) error {
+	return Err{{.GoName}}.WrapDepth(1, err{{args .}})
+}
+{{end}}`))
+
+var mdTemplate = template.Must(template.New("md").Funcs(funcs).Parse(`# {{.Catalog.Package}} errors
+
+<!-- Code generated by serrgen from {{.Source}}. DO NOT EDIT. -->
+
+| Code | Kind | HTTP status | Message | User message | Severity |
+|------|------|-------------|---------|--------------|----------|
+{{- range .Catalog.Errors}}
+| ` + "`{{.Code}}`" + ` | {{cell .Kind}} | {{.HTTPStatusOrDefault}} | {{cell .Message}} | {{cell .UserMsg}} | {{cell .Severity}} |
+{{- end}}
+{{range .Catalog.Errors}}
+## {{.Code}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+- Message: ` + "`{{.Message}}`" + `{{if .ParamNames}}
+- Parameters: {{range $i, $p := .ParamNames}}{{if $i}}, {{end}}` + "`{{$p}}`" + `{{end}}{{end}}{{if .UserMsg}}
+- User message: {{.UserMsg}}{{end}}
+{{end}}`))
+
+type templateData struct {
+	Source  string
+	Catalog *catalog.Catalog
+}
+
+// generateGo renders the Go source for a catalog
+func generateGo(source string, cat *catalog.Catalog) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, templateData{Source: source, Catalog: cat}); err != nil {
+		return nil, serr.Wrap(err, "unable to render Go source")
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, serr.Wrap(err, "generated Go source is invalid", "source", buf.String())
+	}
+	return out, nil
+}
+
+// generateMarkdown renders the documentation for a catalog
+func generateMarkdown(source string, cat *catalog.Catalog) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := mdTemplate.Execute(&buf, templateData{Source: source, Catalog: cat}); err != nil {
+		return nil, serr.Wrap(err, "unable to render markdown")
+	}
+	return buf.Bytes(), nil
+}
+
+// goParams returns the typed parameter list of an entry's constructor
+func goParams(entry catalog.Entry) string {
+	var params []string
+	for _, name := range entry.ParamNames() {
+		params = append(params, paramIdent(name)+" "+entry.ParamType(name))
+	}
+	return strings.Join(params, ", ")
+}
+
+// goArgs returns the arguments passed on to the definition, with a leading comma.
+// A placeholder used more than once in the message is passed for each use
+func goArgs(entry catalog.Entry) string {
+	var sb strings.Builder
+	for _, name := range serr.PlaceholderNames(entry.Message) {
+		sb.WriteString(", " + paramIdent(name))
+	}
+	return sb.String()
+}
+
+// paramIdent converts a placeholder name into a Go parameter name
+func paramIdent(name string) string {
+	ident := catalog.GoIdentifier(name, false)
+	if token.IsKeyword(ident) || ident == "err" {
+		ident += "_"
+	}
+	return ident
+}
+
+// kindExpr returns the Go expression for a kind, or nothing for an unknown kind
+func kindExpr(kind string) string {
+	if kind == "" {
+		return ""
+	}
+	return "serr.Kind" + catalog.GoIdentifier(kind, true)
+}
+
+// mdCell escapes a value for a markdown table cell
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohanthewiz/serr/catalog"
+)
+
+func TestGenerateGo(t *testing.T) {
+	cat, err := catalog.Load("testdata/errors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateGo("errors.json", cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "errors_gen.go", src, 0); err != nil {
+		t.Fatalf("Generated source does not parse: %v", err)
+	}
+
+	got := string(src)
+	for _, expected := range []string{
+		"package apperrs",
+		`ErrUserNotFound = serr.Define("user_not_found", "user {id} not found", serr.KindNotFound, serr.WithUserMsg("We could not find user {id}", "warn"))`,
+		`serr.WithHTTPStatus(402)`,
+		`"maintenance":      ErrMaintenance,`,
+		"func NewPaymentDeclined(amount float64, provider any) error",
+		"return ErrPaymentDeclined.WrapDepth(1, err, amount, provider)",
+		"func NewMaintenance() error",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected generated source to contain %q, got:\n%s", expected, got)
+		}
+	}
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	cat, err := catalog.Load("testdata/errors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := generateMarkdown("errors.json", cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(md)
+	for _, expected := range []string{
+		"| `user_not_found` | not_found | 404 | user {id} not found | We could not find user {id} | warn |",
+		"| `maintenance` | unavailable | 503 |",
+		"- Parameters: `amount`, `provider`",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", expected, got)
+		}
+	}
+}
+
+func TestParamIdent(t *testing.T) {
+	tests := map[string]string{"user_id": "userId", "type": "type_", "err": "err_", "order-total": "orderTotal"}
+	for name, expected := range tests {
+		if got := paramIdent(name); got != expected {
+			t.Errorf("paramIdent(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func TestRunDocWithoutOut(t *testing.T) {
+	doc := filepath.Join(t.TempDir(), "ERRORS.md")
+
+	var stdout bytes.Buffer
+	if err := run("testdata/errors.json", "", doc, "", &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "package apperrs") {
+		t.Errorf("Expected the generated source on stdout, got:\n%s", stdout.String())
+	}
+	md, err := os.ReadFile(doc)
+	if err != nil {
+		t.Fatalf("Expected the documentation to be written: %v", err)
+	}
+	if !strings.Contains(string(md), "`user_not_found`") {
+		t.Errorf("Unexpected documentation:\n%s", md)
+	}
+}
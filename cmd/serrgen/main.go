@@ -0,0 +1,80 @@
+// Command serrgen generates Go error definitions and documentation from an error catalog.
+//
+// For each catalog entry it generates a serr.Definition, typed New and Wrap constructors,
+// and an entry in a code lookup table. Optionally it writes markdown documentation
+// of the catalog for review by product and support teams.
+//
+// Usage
+//
+//	serrgen -catalog errors.json [-out errors_gen.go] [-doc ERRORS.md]
+//
+// See package github.com/rohanthewiz/serr/catalog for the catalog format.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/rohanthewiz/serr/catalog"
+)
+
+func main() {
+	catalogPath := flag.String("catalog", "", "path of the JSON error catalog")
+	out := flag.String("out", "", "path of the generated Go file (default: stdout)")
+	doc := flag.String("doc", "", "path of the generated markdown documentation (optional)")
+	pkg := flag.String("pkg", "", "package name of the generated file (overrides the catalog)")
+	flag.Parse()
+
+	if *catalogPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*catalogPath, *out, *doc, *pkg, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "serrgen:", serr.StringFromErr(err))
+		os.Exit(1)
+	}
+}
+
+// run generates the Go file to out, or to stdout when out is empty, and the documentation to doc if given
+func run(catalogPath, out, doc, pkg string, stdout io.Writer) error {
+	cat, err := catalog.Load(catalogPath)
+	if err != nil {
+		return err
+	}
+	if pkg != "" {
+		cat.Package = pkg
+	}
+	if cat.Package == "" {
+		return serr.New("no package name given in the catalog or with -pkg")
+	}
+
+	source := filepath.Base(catalogPath)
+
+	src, err := generateGo(source, cat)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		if _, err = stdout.Write(src); err != nil {
+			return serr.Wrap(err, "unable to write generated source")
+		}
+	} else if err = os.WriteFile(out, src, 0o644); err != nil {
+		return serr.Wrap(err, "path", out)
+	}
+
+	if doc != "" {
+		md, err := generateMarkdown(source, cat)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(doc, md, 0o644); err != nil {
+			return serr.Wrap(err, "path", doc)
+		}
+	}
+	return nil
+}
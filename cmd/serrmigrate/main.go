@@ -0,0 +1,137 @@
+// Command serrmigrate rewrites error construction in Go source to use serr.
+//
+// It converts
+//
+//	fmt.Errorf("doing x: %w", err)        => serr.Wrap(err, "doing x")
+//	fmt.Errorf("doing %s: %w", x, err)    => serr.WrapF(err, "doing %s", x)
+//	fmt.Errorf("bad value %d", n)         => serr.NewF("bad value %d", n)
+//	errors.New("boom")                    => serr.New("boom")
+//	errors.Wrap(err, "msg")   (pkg/errors) => serr.Wrap(err, "msg")
+//	errors.Wrapf(err, f, ...) (pkg/errors) => serr.WrapF(err, f, ...)
+//	errors.Errorf(f, ...)     (pkg/errors) => serr.NewF(f, ...)
+//
+// Package level sentinel errors (var ErrX = errors.New(...)) are left alone
+// since an SErr is not comparable and would break errors.Is.
+//
+// Usage
+//
+//	serrmigrate [-w] [-l] [path ...]
+//
+// Paths may be files, directories or a directory followed by /... to recurse.
+// Without -w a diff of the proposed changes is printed and nothing is written.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	write = flag.Bool("w", false, "write result to (source) file instead of printing a diff")
+	list  = flag.Bool("l", false, "list files whose source would be changed")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: serrmigrate [-w] [-l] [path ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"./..."}
+	}
+
+	exitCode := 0
+	for _, path := range paths {
+		files, err := goFiles(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 2
+			continue
+		}
+		for _, file := range files {
+			if err := processFile(file); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exitCode = 2
+			}
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func processFile(filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	out, changed, err := rewriteSource(filename, src)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	if *list {
+		fmt.Println(filename)
+	}
+	if *write {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, out, info.Mode().Perm())
+	}
+	if !*list {
+		fmt.Print(unifiedDiff(filename, string(src), string(out)))
+	}
+	return nil
+}
+
+// goFiles expands a path argument into the Go source files it refers to
+func goFiles(path string) (files []string, err error) {
+	recurse := false
+	if strings.HasSuffix(path, "/...") {
+		recurse = true
+		path = strings.TrimSuffix(path, "/...")
+		if path == "" {
+			path = "."
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == path {
+				return nil
+			}
+			name := d.Name()
+			if !recurse || name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".go") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return
+}
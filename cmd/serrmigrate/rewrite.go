@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	serrPath      = "github.com/rohanthewiz/serr"
+	pkgErrorsPath = "github.com/pkg/errors"
+)
+
+// rewriter holds the per-file state of a migration
+type rewriter struct {
+	file *ast.File
+	// local names under which the packages of interest are imported
+	fmtName, stdErrorsName, pkgErrorsName, serrName string
+	changed                                         bool
+}
+
+// rewriteSource migrates a single Go source file.
+// The formatted result is returned along with whether anything changed.
+func rewriteSource(filename string, src []byte) (out []byte, changed bool, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rw := &rewriter{file: file}
+	rw.resolveImports()
+	if rw.fmtName == "" && rw.stdErrorsName == "" && rw.pkgErrorsName == "" {
+		return src, false, nil
+	}
+
+	rw.rewriteCalls()
+	if !rw.changed {
+		return src, false, nil
+	}
+
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, file); err != nil {
+		return nil, false, err
+	}
+	out, err = fixImports(buf.Bytes())
+	return out, err == nil, err
+}
+
+// resolveImports records the local names of fmt, errors, pkg/errors and serr
+func (rw *rewriter) resolveImports() {
+	for _, imp := range rw.file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := ""
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+
+		switch path {
+		case "fmt":
+			rw.fmtName = nameOr(name, "fmt")
+		case "errors":
+			rw.stdErrorsName = nameOr(name, "errors")
+		case pkgErrorsPath:
+			rw.pkgErrorsName = nameOr(name, "errors")
+		case serrPath:
+			rw.serrName = nameOr(name, "serr")
+		}
+	}
+}
+
+func nameOr(name, dflt string) string {
+	if name != "" {
+		return name
+	}
+	return dflt
+}
+
+// rewriteCalls replaces supported call expressions in place
+func (rw *rewriter) rewriteCalls() {
+	sentinels := rw.sentinelCalls()
+
+	ast.Inspect(rw.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || sentinels[call] {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || pkg.Obj != nil { // a local variable shadows the package
+			return true
+		}
+
+		var replaced bool
+		switch {
+		case pkg.Name == rw.fmtName && sel.Sel.Name == "Errorf":
+			replaced = rw.rewriteErrorf(call)
+		case pkg.Name == rw.pkgErrorsName:
+			replaced = rw.rewritePkgErrors(call, sel.Sel.Name)
+		case pkg.Name == rw.stdErrorsName && sel.Sel.Name == "New":
+			replaced = rw.setCall(call, "New", call.Args)
+		}
+		if replaced {
+			rw.changed = true
+		}
+		return true
+	})
+}
+
+// sentinelCalls collects calls used to initialize package level variables.
+// These must remain comparable for errors.Is, so they are not rewritten.
+func (rw *rewriter) sentinelCalls() map[*ast.CallExpr]bool {
+	calls := map[*ast.CallExpr]bool{}
+	for _, decl := range rw.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, val := range spec.(*ast.ValueSpec).Values {
+				if call, ok := val.(*ast.CallExpr); ok {
+					calls[call] = true
+				}
+			}
+		}
+	}
+	return calls
+}
+
+// rewritePkgErrors maps github.com/pkg/errors constructors onto serr
+func (rw *rewriter) rewritePkgErrors(call *ast.CallExpr, fn string) bool {
+	switch fn {
+	case "New":
+		return rw.setCall(call, "New", call.Args)
+	case "Errorf":
+		return rw.setCall(call, "NewF", call.Args)
+	case "Wrap", "WithMessage":
+		return rw.setCall(call, "Wrap", call.Args)
+	case "Wrapf", "WithMessagef":
+		return rw.setCall(call, "WrapF", call.Args)
+	case "WithStack":
+		return rw.setCall(call, "Wrap", call.Args)
+	}
+	return false
+}
+
+// rewriteErrorf converts a fmt.Errorf call.
+// Only a trailing %w verb can be expressed as a serr wrap,
+// calls with %w elsewhere in the format are left unchanged.
+func (rw *rewriter) rewriteErrorf(call *ast.CallExpr) bool {
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return false
+	}
+
+	verbs, ok := scanVerbs(format)
+	if !ok || len(verbs) != len(call.Args)-1 {
+		return false
+	}
+
+	wraps := 0
+	for _, v := range verbs {
+		if v.verb == 'w' {
+			wraps++
+		}
+	}
+
+	if wraps == 0 {
+		if len(verbs) == 0 {
+			return rw.setCall(call, "New", call.Args)
+		}
+		return rw.setCall(call, "NewF", call.Args)
+	}
+
+	last := verbs[len(verbs)-1]
+	if wraps > 1 || last.verb != 'w' || last.end != len(format) {
+		return false
+	}
+
+	msg := strings.TrimRight(format[:last.start], " :-")
+	errArg := call.Args[len(call.Args)-1]
+	fmtArgs := call.Args[1 : len(call.Args)-1]
+
+	switch {
+	case msg == "":
+		return rw.setCall(call, "Wrap", []ast.Expr{errArg})
+	case len(fmtArgs) == 0:
+		msg = strings.ReplaceAll(msg, "%%", "%")
+		return rw.setCall(call, "Wrap", []ast.Expr{errArg, stringLit(msg)})
+	default:
+		return rw.setCall(call, "WrapF", append([]ast.Expr{errArg, stringLit(msg)}, fmtArgs...))
+	}
+}
+
+// setCall turns call into serr.<fn>(args...)
+func (rw *rewriter) setCall(call *ast.CallExpr, fn string, args []ast.Expr) bool {
+	if rw.serrName == "" {
+		rw.serrName = "serr"
+	}
+	call.Fun = &ast.SelectorExpr{X: ast.NewIdent(rw.serrName), Sel: ast.NewIdent(fn)}
+	call.Args = args
+	return true
+}
+
+func stringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// fmtVerb is the position of a formatting verb within a format string
+type fmtVerb struct {
+	verb       byte
+	start, end int
+}
+
+// scanVerbs lists the verbs in a Printf style format.
+// ok is false for formats we don't attempt to rewrite (explicit argument indexes, * widths).
+func scanVerbs(format string) (verbs []fmtVerb, ok bool) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) || format[i] == '[' || format[i] == '*' {
+			return nil, false
+		}
+		verbs = append(verbs, fmtVerb{verb: format[i], start: start, end: i + 1})
+	}
+	return verbs, true
+}
+
+// fixImports adds the serr import and drops imports no longer referenced.
+// Imports are edited textually on the printed source, which keeps comments and grouping intact.
+func fixImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	hasSerr := false
+	var lastImport *ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		lastImport = gen
+
+		var specEdits []edit
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(imp.Path.Value)
+			switch path {
+			case serrPath:
+				hasSerr = true
+			case "fmt", "errors", pkgErrorsPath:
+				if !used[nameOr(identName(imp.Name), lastElem(path))] {
+					start, end := lineBounds(src, offset(imp.Pos()), offset(imp.End()))
+					specEdits = append(specEdits, edit{start, end, ""})
+				}
+			}
+		}
+
+		if len(specEdits) < len(gen.Specs) {
+			edits = append(edits, specEdits...)
+			continue
+		}
+		// Every spec is unused so the whole declaration goes
+		start, end := lineBounds(src, offset(gen.Pos()), offset(gen.End()))
+		edits = append(edits, edit{start, end, ""})
+		if lastImport == gen {
+			lastImport = nil
+		}
+	}
+
+	if !hasSerr {
+		serrSpec := strconv.Quote(serrPath)
+		switch {
+		case lastImport == nil:
+			pos := offset(file.Name.End())
+			edits = append(edits, edit{pos, pos, "\n\nimport " + serrSpec})
+		case lastImport.Lparen.IsValid():
+			pos := offset(lastImport.Rparen)
+			edits = append(edits, edit{pos, pos, "\n\t" + serrSpec + "\n"})
+		default:
+			spec := lastImport.Specs[0]
+			start, end := offset(spec.Pos()), offset(spec.End())
+			edits = append(edits, edit{start, end, "(\n\t" + string(src[start:end]) + "\n\n\t" + serrSpec + "\n)"})
+		}
+	}
+
+	// Apply edits back to front so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return format.Source(out)
+}
+
+// lineBounds widens the byte range [start, end) to cover whole lines
+func lineBounds(src []byte, start, end int) (int, int) {
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+	if end < len(src) {
+		end++
+	}
+	return start, end
+}
+
+func identName(id *ast.Ident) string {
+	if id == nil {
+		return ""
+	}
+	return id.Name
+}
+
+func lastElem(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
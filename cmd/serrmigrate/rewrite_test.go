@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteSource(t *testing.T) {
+	const src = `package demo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func read(name string) error {
+	if _, err := os.Open(name); err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	if name == "" {
+		return errors.New("no name")
+	}
+	if len(name) > 10 {
+		return fmt.Errorf("name %q too long by %d", name, len(name)-10)
+	}
+	return fmt.Errorf("reading %s: %w", name, ErrNotFound)
+}
+`
+	const want = `package demo
+
+import (
+	"errors"
+	"os"
+
+	"github.com/rohanthewiz/serr"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func read(name string) error {
+	if _, err := os.Open(name); err != nil {
+		return serr.Wrap(err, "opening file")
+	}
+	if name == "" {
+		return serr.New("no name")
+	}
+	if len(name) > 10 {
+		return serr.NewF("name %q too long by %d", name, len(name)-10)
+	}
+	return serr.WrapF(ErrNotFound, "reading %s", name)
+}
+`
+	out, changed, err := rewriteSource("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected source to be changed")
+	}
+	if string(out) != want {
+		t.Errorf("unexpected rewrite. Got:\n%s\nWant:\n%s", out, want)
+	}
+}
+
+func TestRewriteSourcePkgErrors(t *testing.T) {
+	const src = `package demo
+
+import "github.com/pkg/errors"
+
+func save(err error, id int) error {
+	if id == 0 {
+		return errors.Wrap(err, "saving")
+	}
+	return errors.Wrapf(err, "saving %d", id)
+}
+`
+	out, changed, err := rewriteSource("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected source to be changed")
+	}
+
+	got := string(out)
+	for _, expected := range []string{`"github.com/rohanthewiz/serr"`, `serr.Wrap(err, "saving")`, `serr.WrapF(err, "saving %d", id)`} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected output to contain %s, got:\n%s", expected, got)
+		}
+	}
+	if strings.Contains(got, "pkg/errors") {
+		t.Errorf("Expected pkg/errors import to be removed, got:\n%s", got)
+	}
+}
+
+func TestRewriteSourceUnsupported(t *testing.T) {
+	const src = `package demo
+
+import "fmt"
+
+func f(a, b error) error {
+	return fmt.Errorf("%w then %w", a, b)
+}
+`
+	_, changed, err := rewriteSource("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("Errorf with more than one %w should be left unchanged")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\n"
+	b := "one\n2\nthree\nfour\n"
+
+	const want = `--- f.go
++++ f.go
+@@ -1,4 +1,4 @@
+ one
+-two
++2
+ three
+ four
+`
+	if got := unifiedDiff("f.go", a, b); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package serr
+
+import (
+	"fmt"
+)
+
+// Definition is a reusable error declared once and instantiated with parameters.
+// Its message uses the named placeholder syntax of NewN, so each parameter becomes an attribute.
+// Errors created from a Definition match it with errors.Is
+// Example:
+//
+//	var ErrUserNotFound = serr.Define("user_not_found", "user {id} not found", serr.KindNotFound)
+//
+//	err := ErrUserNotFound.New(userID)
+//	errors.Is(err, ErrUserNotFound) // => true
+type Definition struct {
+	code            string
+	format          string
+	kind            Kind
+	httpStatus      int
+	userMsg         string
+	userMsgSeverity string
+}
+
+// DefineOption configures a Definition
+type DefineOption interface {
+	applyTo(def *Definition)
+}
+
+type defineOptionFunc func(def *Definition)
+
+func (f defineOptionFunc) applyTo(def *Definition) {
+	f(def)
+}
+
+// WithUserMsg sets the user message and severity of errors created from a definition.
+// The message may reference the definition's placeholders by name, e.g. "User {id} was not found"
+func WithUserMsg(msg string, severity string) DefineOption {
+	return defineOptionFunc(func(def *Definition) {
+		def.userMsg, def.userMsgSeverity = msg, severity
+	})
+}
+
+// WithHTTPStatus sets the HTTP status of errors created from a definition,
+// overriding the status implied by its kind
+func WithHTTPStatus(status int) DefineOption {
+	return defineOptionFunc(func(def *Definition) {
+		def.httpStatus = status
+	})
+}
+
+// applyTo allows a Kind to be passed directly to Define
+func (k Kind) applyTo(def *Definition) {
+	def.kind = k
+}
+
+// Define declares a reusable error with a unique code and a message format
+func Define(code, format string, opts ...DefineOption) *Definition {
+	def := &Definition{code: code, format: format}
+	for _, opt := range opts {
+		opt.applyTo(def)
+	}
+	return def
+}
+
+// Error allows a Definition to be used as the target of errors.Is
+func (def *Definition) Error() string {
+	return def.code
+}
+
+// Code returns the code of the definition
+func (def *Definition) Code() string {
+	return def.code
+}
+
+// Kind returns the kind of the definition
+func (def *Definition) Kind() Kind {
+	return def.kind
+}
+
+// Format returns the message format of the definition
+func (def *Definition) Format() string {
+	return def.format
+}
+
+// HTTPStatus returns the HTTP status of the definition,
+// which defaults to the status implied by its kind
+func (def *Definition) HTTPStatus() int {
+	if def.httpStatus != 0 {
+		return def.httpStatus
+	}
+	return def.kind.HTTPStatus()
+}
+
+// UserMsg returns the user message format and severity of the definition
+func (def *Definition) UserMsg() (msg, severity string) {
+	return def.userMsg, def.userMsgSeverity
+}
+
+// New instantiates the definition. Args fill the placeholders of the message format in order
+func (def *Definition) New(args ...any) error {
+	return def.instantiate(nil, 0, args)
+}
+
+// NewDepth is like New but skips depth additional frames when recording the caller context.
+// This lets helper functions, such as generated constructors, report their caller's location
+func (def *Definition) NewDepth(depth int, args ...any) error {
+	return def.instantiate(nil, depth, args)
+}
+
+// Wrap instantiates the definition as a wrapper of err.
+// Args fill the placeholders of the message format in order.
+// The result matches both the definition and err with errors.Is
+func (def *Definition) Wrap(err error, args ...any) error {
+	if err == nil {
+		fmt.Println("SErr: Not wrapping a nil error", "callerLocation:", FunctionLoc(FrameLevels.FrameLevel2),
+			"callerName:", FunctionName(FrameLevels.FrameLevel2))
+		return nil
+	}
+	return def.instantiate(err, 0, args)
+}
+
+// WrapDepth is like Wrap but skips depth additional frames when recording the caller context
+func (def *Definition) WrapDepth(depth int, err error, args ...any) error {
+	if err == nil {
+		fmt.Println("SErr: Not wrapping a nil error", "callerLocation:", FunctionLoc(FrameLevels.FrameLevel2+depth),
+			"callerName:", FunctionName(FrameLevels.FrameLevel2+depth))
+		return nil
+	}
+	return def.instantiate(err, depth, args)
+}
+
+// instantiate builds the SErr for New, Wrap and their Depth variants
+// It must be called directly from those, as the caller context frame level depends on it
+func (def *Definition) instantiate(cause error, depth int, args []any) error {
+	msg, attrs := formatNamed(def.format, args...)
+
+	se := withAttributes(NewSerrNoContext(cause), def.fields(attrs)...)
+	se.err = definedError{def: def, msg: msg, cause: se.err}
+	out := se.newSErrAt(FrameLevels.FrameLevel5 + depth)
+
+	// The user message follows the caller context, as with SetUserMsg, so it is attributed to this layer
+	if def.userMsg != "" {
+		out.SetUserMsg(expandNamed(def.userMsg, attrs), def.userMsgSeverity)
+	}
+	return out
+}
+
+// fields returns the definition's attributes followed by attrs
+func (def *Definition) fields(attrs []any) []any {
+	fields := []any{CodeKey, def.code}
+	if def.kind != KindUnknown {
+		fields = append(fields, KindKey, def.kind)
+	}
+	if def.httpStatus != 0 {
+		fields = append(fields, HTTPStatusKey, def.httpStatus)
+	}
+	return append(fields, attrs...)
+}
+
+// definedError is the core error of an SErr instantiated from a Definition
+type definedError struct {
+	def   *Definition
+	msg   string
+	cause error
+}
+
+func (e definedError) Error() string {
+	if e.cause != nil {
+		return e.msg + ": " + e.cause.Error()
+	}
+	return e.msg
+}
+
+// Is reports whether target is the definition this error was created from
+func (e definedError) Is(target error) bool {
+	def, ok := target.(*Definition)
+	return ok && def == e.def
+}
+
+func (e definedError) Unwrap() error {
+	return e.cause
+}
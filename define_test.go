@@ -0,0 +1,118 @@
+package serr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var errUserNotFound = Define("user_not_found", "user {id} not found", KindNotFound)
+var errQuotaExceeded = Define("quota_exceeded", "quota of {limit} exceeded")
+
+func TestDefinitionNew(t *testing.T) {
+	err := errUserNotFound.New(42)
+
+	if err.Error() != "user 42 not found" {
+		t.Errorf("Expected error message 'user 42 not found', got '%s'", err.Error())
+	}
+	if !errors.Is(err, errUserNotFound) {
+		t.Error("Expected error to match its definition with errors.Is")
+	}
+	if errors.Is(err, errQuotaExceeded) {
+		t.Error("Expected error not to match another definition")
+	}
+
+	se, ok := err.(SErr)
+	if !ok {
+		t.Fatal("Definition.New should return a SErr type")
+	}
+	mp := se.FieldsMap()
+	for key, expected := range map[string]string{"id": "42", "code": "user_not_found", "kind": "not_found"} {
+		if mp[key] != expected {
+			t.Errorf("Expected attribute '%s' to be '%s', got '%s'", key, expected, mp[key])
+		}
+	}
+	if !strings.Contains(mp["location"], "define_test.go:") {
+		t.Errorf("Expected location to be the caller, got '%s'", mp["location"])
+	}
+
+	// Still matches after further wrapping
+	wrapped := fmt.Errorf("handler: %w", Wrap(err, "layer", "service"))
+	if !errors.Is(wrapped, errUserNotFound) {
+		t.Error("Expected wrapped error to match its definition with errors.Is")
+	}
+	if KindOf(wrapped) != KindNotFound {
+		t.Errorf("Expected kind '%s', got '%s'", KindNotFound, KindOf(wrapped))
+	}
+	if CodeOf(wrapped) != "user_not_found" {
+		t.Errorf("Expected code 'user_not_found', got '%s'", CodeOf(wrapped))
+	}
+}
+
+func TestDefinitionWrap(t *testing.T) {
+	if errQuotaExceeded.Wrap(nil, 10) != nil {
+		t.Error("Definition.Wrap(nil, ...) should return nil")
+	}
+
+	base := NewSErr("read failed", "file", "a.txt", "cause", io.EOF.Error())
+	err := errQuotaExceeded.Wrap(base, 10)
+	if err.Error() != "quota of 10 exceeded: read failed" {
+		t.Errorf("Unexpected error message '%s'", err.Error())
+	}
+	if RefFromErr(err) != base.Ref() {
+		t.Error("Expected the reference ID of the wrapped SErr to be kept")
+	}
+	if !errors.Is(err, errQuotaExceeded) {
+		t.Error("Expected error to match its definition with errors.Is")
+	}
+
+	err = errQuotaExceeded.Wrap(io.EOF, 10)
+	if !errors.Is(err, io.EOF) {
+		t.Error("Expected error to match the wrapped error with errors.Is")
+	}
+	if KindOf(err) != KindUnknown {
+		t.Errorf("Expected unknown kind, got '%s'", KindOf(err))
+	}
+}
+
+func TestKindHTTPStatus(t *testing.T) {
+	tests := map[Kind]int{
+		KindNotFound:     http.StatusNotFound,
+		KindInvalid:      http.StatusBadRequest,
+		KindUnauthorized: http.StatusUnauthorized,
+		KindUnknown:      http.StatusInternalServerError,
+	}
+	for kind, expected := range tests {
+		if got := kind.HTTPStatus(); got != expected {
+			t.Errorf("Expected %s to map to %d, got %d", kind, expected, got)
+		}
+	}
+}
+
+func TestDefinitionOptions(t *testing.T) {
+	def := Define("payment_declined", "payment of {amount:%.2f} declined", KindInvalid,
+		WithUserMsg("Your payment of {amount:%.2f} was declined", Severity.Error), WithHTTPStatus(http.StatusPaymentRequired))
+
+	newDeclined := func(amount float64) error {
+		return def.NewDepth(1, amount)
+	}
+	err := newDeclined(12.5)
+
+	if msg, sev := UserMsg(err); msg != "Your payment of 12.50 was declined" || sev != Severity.Error {
+		t.Errorf("Unexpected user message '%s' with severity '%s'", msg, sev)
+	}
+	if HTTPStatusOf(err) != http.StatusPaymentRequired {
+		t.Errorf("Expected HTTP status %d, got %d", http.StatusPaymentRequired, HTTPStatusOf(err))
+	}
+	if def.HTTPStatus() != http.StatusPaymentRequired {
+		t.Errorf("Expected definition HTTP status %d, got %d", http.StatusPaymentRequired, def.HTTPStatus())
+	}
+
+	// NewDepth skips the helper so the location is this test function
+	if fn := err.(SErr).FieldsMap()["function"]; fn != "rohanthewiz/serr.TestDefinitionOptions" {
+		t.Errorf("Expected function to be the helper's caller, got '%s'", fn)
+	}
+}
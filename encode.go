@@ -0,0 +1,390 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Field is an attribute of an error, as rendered by the encoders
+type Field struct {
+	Key   string
+	Value any
+}
+
+// encodeLeadKeys are rendered first, in this order, by all encoders
+var encodeLeadKeys = []string{CodeKey, KindKey, HTTPStatusKey, SeverityKey, UserMsgKey, UserMsgSeverityKey}
+
+// OrderedFields returns the attributes of err in the order shared by the encoders:
+// the well-known keys (code, kind, httpStatus, severity, user message), then the other attributes
+// in the order they were first added, then the location and function where the error originated.
+// Duplicate values are joined as in FieldsMapOfAny
+func OrderedFields(err error) (fields []Field) {
+	var ser SErr
+	if !errors.As(err, &ser) {
+		return nil
+	}
+
+	values := ser.FieldsMapOfAny()
+	var rest []string
+	for i := 0; i+1 < len(ser.fields); i += 2 {
+		key := fmt.Sprintf("%v", ser.fields[i])
+		if key == "location" || key == "function" || contains(encodeLeadKeys, key) || contains(rest, key) {
+			continue
+		}
+		rest = append(rest, key)
+	}
+
+	for _, key := range append(append([]string(nil), encodeLeadKeys...), rest...) {
+		if val, ok := values[key]; ok {
+			fields = append(fields, Field{Key: key, Value: val})
+		}
+	}
+
+	// The innermost location is where the error originated; the others are in Frames
+	origins := ser.FieldsMapOfSliceOfAny()
+	for _, key := range []string{"location", "function"} {
+		if vals := origins[key]; len(vals) > 0 {
+			fields = append(fields, Field{Key: key, Value: vals[0]})
+		}
+	}
+	return
+}
+
+// EncodeOptions is the context, beyond the error itself, used by the encoders
+type EncodeOptions struct {
+	Time time.Time // defaults to now
+	Host string    // GELF host, defaults to os.Hostname
+	// CEF device identification, defaulting to "serr", "serr" and "1"
+	DeviceVendor, DeviceProduct, DeviceVersion string
+}
+
+func (o EncodeOptions) withDefaults() EncodeOptions {
+	if o.Time.IsZero() {
+		o.Time = time.Now()
+	}
+	if o.Host == "" {
+		o.Host, _ = os.Hostname()
+	}
+	if o.DeviceVendor == "" {
+		o.DeviceVendor = "serr"
+	}
+	if o.DeviceProduct == "" {
+		o.DeviceProduct = "serr"
+	}
+	if o.DeviceVersion == "" {
+		o.DeviceVersion = "1"
+	}
+	return o
+}
+
+// JSONSafeFields returns fields with the values encoding/json can't encode, like channels,
+// functions or cyclic structures, replaced by their %v string.
+// Sinks use it so that one odd attribute doesn't lose the whole event
+func JSONSafeFields(fields map[string]any) map[string]any {
+	if _, err := json.Marshal(fields); err == nil {
+		return fields
+	}
+	safe := make(map[string]any, len(fields))
+	for key, val := range fields {
+		if _, err := json.Marshal(val); err != nil {
+			val = fmt.Sprintf("%v", val)
+		}
+		safe[key] = val
+	}
+	return safe
+}
+
+// EncodeLogfmt renders err as a logfmt line (without newline):
+//
+//	time=2026-10-18T12:00:00Z level=error msg="db down" ref=01J... code=db_down table=users location=app/db.go:42
+func EncodeLogfmt(err error, opts EncodeOptions) []byte {
+	opts = opts.withDefaults()
+	var b []byte
+	add := func(key string, val any) {
+		if len(b) > 0 {
+			b = append(b, ' ')
+		}
+		b = append(b, sanitizeKey(key, isLogfmtKeyChar, '_')...)
+		b = append(b, '=')
+		s := fieldString(val)
+		if s == "" || strings.ContainsAny(s, " =\"\\") || strings.IndexFunc(s, unicode.IsControl) >= 0 {
+			b = append(b, '"')
+			b = appendEscaped(b, s, `"\`)
+			b = append(b, '"')
+		} else {
+			b = append(b, s...)
+		}
+	}
+
+	add("time", opts.Time.UTC().Format(time.RFC3339Nano))
+	add("level", SeverityOf(err).String())
+	add("msg", err.Error())
+	if ref := RefFromErr(err); ref != "" {
+		add("ref", ref)
+	}
+	for _, f := range OrderedFields(err) {
+		if f.Key != SeverityKey {
+			add(f.Key, f.Value)
+		}
+	}
+	return b
+}
+
+// EncodeGELF renders err as a Graylog GELF 1.1 message.
+// Attributes become additional fields, prefixed with an underscore
+func EncodeGELF(err error, opts EncodeOptions) ([]byte, error) {
+	opts = opts.withDefaults()
+	msg := map[string]any{
+		"version":       "1.1",
+		"host":          opts.Host,
+		"short_message": err.Error(),
+		"full_message":  StringFromErr(err),
+		"timestamp":     math.Round(float64(opts.Time.UnixNano())/1e6) / 1e3,
+		"level":         SeverityOf(err).SyslogSeverity(),
+	}
+	if ref := RefFromErr(err); ref != "" {
+		msg["_ref"] = ref
+	}
+	for _, f := range OrderedFields(err) {
+		key := "_" + sanitizeKey(f.Key, isGELFKeyChar, '_')
+		if key == "_id" || f.Key == SeverityKey {
+			continue // _id is reserved, the severity is the level
+		}
+		switch v := f.Value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			msg[key] = v // GELF only accepts numbers and strings
+		default:
+			msg[key] = fieldString(v)
+		}
+	}
+
+	byts, jerr := json.Marshal(msg)
+	if jerr != nil {
+		return nil, Wrap(jerr, "unable to encode GELF message")
+	}
+	return byts, nil
+}
+
+// ecsVersion is the Elastic Common Schema version the ECS encoder follows
+const ecsVersion = "8.11.0"
+
+// EncodeECS renders err as an Elastic Common Schema document: error.message, error.type,
+// error.code, error.stack_trace (one frame per wrap), log.level, log.origin.* and labels.* for the attributes
+func EncodeECS(err error, opts EncodeOptions) ([]byte, error) {
+	opts = opts.withDefaults()
+	errDoc := map[string]any{
+		"message": err.Error(),
+		"type":    errorType(err),
+	}
+	log := map[string]any{"level": SeverityOf(err).String()}
+	labels := map[string]string{}
+
+	if code := CodeOf(err); code != "" {
+		errDoc["code"] = code
+	}
+	var ser SErr
+	if errors.As(err, &ser) {
+		if trace := stackTrace(ser.Frames()); trace != "" {
+			errDoc["stack_trace"] = trace
+		}
+	}
+	if ref := RefFromErr(err); ref != "" {
+		labels["ref"] = ref
+	}
+
+	origin := map[string]any{}
+	for _, f := range OrderedFields(err) {
+		switch f.Key {
+		case CodeKey, SeverityKey:
+			// error.code and log.level
+		case "location":
+			file, line := splitLocation(fieldString(f.Value))
+			origin["file"] = map[string]any{"name": file, "line": line}
+		case "function":
+			origin["function"] = fieldString(f.Value)
+		default:
+			// Label keys can't contain dots
+			labels[sanitizeKey(f.Key, isECSLabelChar, '_')] = fieldString(f.Value)
+		}
+	}
+	if len(origin) > 0 {
+		log["origin"] = origin
+	}
+
+	doc := map[string]any{
+		"@timestamp": opts.Time.UTC().Format(time.RFC3339Nano),
+		"message":    err.Error(),
+		"error":      errDoc,
+		"log":        log,
+		"ecs":        map[string]string{"version": ecsVersion},
+	}
+	if len(labels) > 0 {
+		doc["labels"] = labels
+	}
+
+	byts, jerr := json.Marshal(doc)
+	if jerr != nil {
+		return nil, Wrap(jerr, "unable to encode ECS document")
+	}
+	return byts, nil
+}
+
+// EncodeCEF renders err as an ArcSight Common Event Format line:
+//
+//	CEF:0|vendor|product|version|code|message|severity|rt=... externalId=ref key=value ...
+//
+// The signature ID is the error code ("error" without one) and the severity is scaled to 0-10
+func EncodeCEF(err error, opts EncodeOptions) []byte {
+	opts = opts.withDefaults()
+	sigID := CodeOf(err)
+	if sigID == "" {
+		sigID = "error"
+	}
+
+	var b []byte
+	b = append(b, "CEF:0"...)
+	for _, h := range []string{opts.DeviceVendor, opts.DeviceProduct, opts.DeviceVersion, sigID, err.Error(),
+		strconv.Itoa(cefSeverity(SeverityOf(err)))} {
+		b = append(b, '|')
+		b = appendEscaped(b, h, `|\`)
+	}
+	b = append(b, '|')
+
+	first := true
+	add := func(key string, val any) {
+		if !first {
+			b = append(b, ' ')
+		}
+		first = false
+		b = append(b, sanitizeKey(key, isCEFKeyChar, -1)...)
+		b = append(b, '=')
+		b = appendEscaped(b, fieldString(val), `=\`)
+	}
+	add("rt", opts.Time.UnixMilli())
+	if ref := RefFromErr(err); ref != "" {
+		add("externalId", ref)
+	}
+	for _, f := range OrderedFields(err) {
+		if f.Key != CodeKey && f.Key != SeverityKey {
+			add(f.Key, f.Value)
+		}
+	}
+	return b
+}
+
+// cefSeverity scales a severity onto CEF's 0 to 10
+func cefSeverity(sev SeverityLevel) int {
+	switch {
+	case sev <= SeverityDebug:
+		return 1
+	case sev <= SeveritySuccess:
+		return 3
+	case sev == SeverityWarn:
+		return 5
+	case sev == SeverityError:
+		return 7
+	}
+	return 10
+}
+
+// appendEscaped appends s to b, backslash escaping the characters in specials
+// and writing newlines, carriage returns and tabs as \n, \r and \t
+func appendEscaped(b []byte, s, specials string) []byte {
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b = append(b, `\n`...)
+		case r == '\r':
+			b = append(b, `\r`...)
+		case r == '\t':
+			b = append(b, `\t`...)
+		case strings.ContainsRune(specials, r):
+			b = append(b, '\\', byte(r))
+		default:
+			b = append(b, string(r)...)
+		}
+	}
+	return b
+}
+
+// sanitizeKey replaces the characters of key not allowed by a format with replacement,
+// or drops them when replacement is negative
+func sanitizeKey(key string, allowed func(r rune) bool, replacement rune) string {
+	return strings.Map(func(r rune) rune {
+		if allowed(r) {
+			return r
+		}
+		return replacement
+	}, key)
+}
+
+func isLogfmtKeyChar(r rune) bool {
+	return r > ' ' && r != '=' && r != '"' && r < unicode.MaxASCII
+}
+
+func isGELFKeyChar(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-')
+}
+
+func isECSLabelChar(r rune) bool {
+	return r != '.' && r != '*' && r != '\\' && !unicode.IsSpace(r)
+}
+
+func isCEFKeyChar(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// fieldString renders an attribute value as text
+func fieldString(val any) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// errorType is the error code, or else the Go type of the innermost error
+func errorType(err error) string {
+	if code := CodeOf(err); code != "" {
+		return code
+	}
+	for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(err) {
+		err = inner
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// stackTrace renders frames, innermost first, in the style of a Go panic trace
+func stackTrace(frames []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&b, "%s()\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// splitLocation splits a "file:line" location
+func splitLocation(loc string) (file string, line int) {
+	if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+		if n, err := strconv.Atoi(loc[i+1:]); err == nil {
+			return loc[:i], n
+		}
+	}
+	return loc, 0
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package serr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var encodeTime = time.Date(2026, 10, 18, 12, 0, 0, 500_000_000, time.UTC)
+
+func encodeTestErr() error {
+	inner := New("db down", "table", "users", "query", `select "x"`)
+	return Wrap(inner, CodeKey, "db_down", "retries", "3", "note", "a=b|c\nd")
+}
+
+func TestOrderedFields(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	var keys []string
+	for _, f := range OrderedFields(encodeTestErr()) {
+		keys = append(keys, f.Key)
+	}
+	expected := "code table query retries note location function"
+	if strings.Join(keys, " ") != expected {
+		t.Errorf("Expected keys '%s', got '%s'", expected, strings.Join(keys, " "))
+	}
+	if OrderedFields(errPlain("plain")) != nil {
+		t.Error("Expected no fields for a plain error")
+	}
+}
+
+func TestEncodeLogfmt(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	line := string(EncodeLogfmt(encodeTestErr(), EncodeOptions{Time: encodeTime}))
+	prefix := `time=2026-10-18T12:00:00.5Z level=error msg="db down" ref=01TESTREF code=db_down table=users query="select \"x\"" retries=3 note="a=b|c\nd" location=serr/encode_test.go:`
+	if !strings.HasPrefix(line, prefix) {
+		t.Errorf("Expected logfmt line to start with\n%s\ngot\n%s", prefix, line)
+	}
+	if !strings.HasSuffix(line, " function=rohanthewiz/serr.encodeTestErr") {
+		t.Errorf("Expected the origin function last, got %s", line)
+	}
+}
+
+func TestEncodeGELF(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	ser := NewSErr("quota exceeded", "id", "x", "tenant.name", "acme")
+	ser.AppendAttributes("limit", 100)
+	byts, err := EncodeGELF(ser, EncodeOptions{Time: encodeTime, Host: "edge-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg map[string]any
+	if err = json.Unmarshal(byts, &msg); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"version": "1.1", "host": "edge-1", "short_message": "quota exceeded", "timestamp": 1792324800.5,
+		"level": float64(3), "_ref": "01TESTREF", "_tenant.name": "acme", "_limit": float64(100),
+	}
+	for key, val := range expected {
+		if msg[key] != val {
+			t.Errorf("Expected %s to be %v, got %v", key, val, msg[key])
+		}
+	}
+	if _, ok := msg["_id"]; ok {
+		t.Error("Expected the reserved _id field to be skipped")
+	}
+}
+
+func TestEncodeECS(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	byts, err := EncodeECS(encodeTestErr(), EncodeOptions{Time: encodeTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Timestamp string `json:"@timestamp"`
+		Error     struct {
+			Message, Type, Code string
+			StackTrace          string `json:"stack_trace"`
+		}
+		Log struct {
+			Level  string
+			Origin struct {
+				File struct {
+					Name string
+					Line int
+				}
+				Function string
+			}
+		}
+		Labels map[string]string
+	}
+	if err = json.Unmarshal(byts, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Timestamp != "2026-10-18T12:00:00.5Z" || doc.Error.Message != "db down" || doc.Error.Type != "db_down" || doc.Error.Code != "db_down" {
+		t.Errorf("Unexpected ECS document %s", byts)
+	}
+	if strings.Count(doc.Error.StackTrace, "github.com/rohanthewiz/serr.encodeTestErr()") != 2 {
+		t.Errorf("Expected a stack frame per wrap, got %q", doc.Error.StackTrace)
+	}
+	if doc.Log.Level != "error" || doc.Log.Origin.File.Name != "serr/encode_test.go" || doc.Log.Origin.File.Line == 0 {
+		t.Errorf("Unexpected log fields %+v", doc.Log)
+	}
+	if doc.Labels["ref"] != "01TESTREF" || doc.Labels["table"] != "users" || doc.Labels["retries"] != "3" {
+		t.Errorf("Unexpected labels %v", doc.Labels)
+	}
+}
+
+func TestEncodeCEF(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	line := string(EncodeCEF(encodeTestErr(), EncodeOptions{Time: encodeTime, DeviceVendor: "Acme|Corp"}))
+	prefix := `CEF:0|Acme\|Corp|serr|1|db_down|db down|7|rt=1792324800500 externalId=01TESTREF table=users query=select "x" retries=3 note=a\=b|c\nd location=serr/encode_test.go:`
+	if !strings.HasPrefix(line, prefix) {
+		t.Errorf("Expected CEF line to start with\n%s\ngot\n%s", prefix, line)
+	}
+}
+
+func TestJSONSafeFields(t *testing.T) {
+	ch := make(chan int)
+	fields := map[string]any{"count": 3, "name": "x", "ch": ch}
+
+	safe := JSONSafeFields(fields)
+	if _, err := json.Marshal(safe); err != nil {
+		t.Fatalf("Expected the fields to encode, got %v", err)
+	}
+	if safe["count"] != 3 || safe["name"] != "x" || safe["ch"] != fmt.Sprintf("%v", ch) {
+		t.Errorf("Expected only the channel to become a string, got %v", safe)
+	}
+	if _, ok := fields["ch"].(chan int); !ok {
+		t.Error("Expected the original fields to be left unchanged")
+	}
+}
+
+type errPlain string
+
+func (e errPlain) Error() string { return string(e) }
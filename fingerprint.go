@@ -0,0 +1,92 @@
+package serr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Normalizer rewrites an error message so that occurrences differing only in variable parts,
+// like IDs or counts, get the same fingerprint
+type Normalizer func(msg string) string
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	quotedPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` + "`[^`]*`")
+	numberPattern = regexp.MustCompile(`(?i)0x[0-9a-f]+|\d+(?:\.\d+)?`)
+)
+
+// NormalizeUUIDs replaces UUIDs with "<uuid>"
+func NormalizeUUIDs(msg string) string {
+	return uuidPattern.ReplaceAllString(msg, "<uuid>")
+}
+
+// NormalizeQuoted replaces double, single and back quoted strings with "<str>"
+func NormalizeQuoted(msg string) string {
+	return quotedPattern.ReplaceAllString(msg, "<str>")
+}
+
+// NormalizeNumbers replaces decimal and hexadecimal numbers with "<n>"
+func NormalizeNumbers(msg string) string {
+	return numberPattern.ReplaceAllString(msg, "<n>")
+}
+
+// DefaultNormalizers are applied by Fingerprint until SetNormalizers is called.
+// UUIDs go first as they contain numbers
+var DefaultNormalizers = []Normalizer{NormalizeUUIDs, NormalizeQuoted, NormalizeNumbers}
+
+var normalizers atomic.Pointer[[]Normalizer]
+
+// SetNormalizers replaces the normalizers applied by Fingerprint, in order, returning the previous ones.
+// Pass DefaultNormalizers along with your own to extend them
+func SetNormalizers(ns ...Normalizer) (previous []Normalizer) {
+	ns = append([]Normalizer(nil), ns...)
+	if prev := normalizers.Swap(&ns); prev != nil {
+		return *prev
+	}
+	return DefaultNormalizers
+}
+
+// NormalizeMessage applies the Fingerprint normalizers to msg
+func NormalizeMessage(msg string) string {
+	ns := DefaultNormalizers
+	if p := normalizers.Load(); p != nil {
+		ns = *p
+	}
+	for _, n := range ns {
+		msg = n(msg)
+	}
+	return msg
+}
+
+// Fingerprint returns a stable hash grouping recurring occurrences of err.
+// It covers the type and normalized message of the root error, the error code
+// and the trail of functions and files where it was created and wrapped.
+// Line numbers are left out, so the fingerprint survives unrelated edits between releases.
+// It is empty for a nil error
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	root := err
+	for inner := errors.Unwrap(root); inner != nil; inner = errors.Unwrap(root) {
+		root = inner
+	}
+	parts := []string{fmt.Sprintf("%T", root), NormalizeMessage(root.Error()), CodeOf(err)}
+
+	var ser SErr
+	if errors.As(err, &ser) {
+		for _, frame := range ser.Frames() {
+			parts = append(parts, frame.Function+" "+path.Base(frame.File))
+		}
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
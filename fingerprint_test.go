@@ -0,0 +1,69 @@
+package serr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	msg := `user 42 not found in "tenant-a" (request 3f2a8c1e-9b4d-4e6f-8a2b-1c3d5e7f9a0b, 0.5s, addr 0xc000123)`
+	expected := `user <n> not found in <str> (request <uuid>, <n>s, addr <n>)`
+	if norm := NormalizeMessage(msg); norm != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, norm)
+	}
+}
+
+func fingerprintLookup(id int) error {
+	return New(fmt.Sprintf("order %d not found", id), CodeKey, "order_not_found")
+}
+
+func fingerprintHandler(id int) error {
+	return Wrap(fingerprintLookup(id), "handler", "orders")
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := Fingerprint(fingerprintHandler(1))
+	if len(fp) != 32 {
+		t.Errorf("Expected a 32 character fingerprint, got '%s'", fp)
+	}
+	if other := Fingerprint(fingerprintHandler(987)); other != fp {
+		t.Errorf("Expected errors differing in numbers to share a fingerprint, got %s and %s", fp, other)
+	}
+	if direct := Fingerprint(fingerprintLookup(1)); direct == fp {
+		t.Error("Expected a different wrap trail to change the fingerprint")
+	}
+	if coded := Fingerprint(Wrap(fingerprintLookup(1), CodeKey, "other")); coded == Fingerprint(Wrap(fingerprintLookup(1))) {
+		t.Error("Expected the code to change the fingerprint")
+	}
+	if Fingerprint(nil) != "" {
+		t.Error("Expected no fingerprint for nil")
+	}
+}
+
+func TestFingerprintIgnoresLines(t *testing.T) {
+	first := New("timeout")
+	second := New("timeout")
+	if Fingerprint(first) != Fingerprint(second) {
+		t.Error("Expected errors created on different lines of a function to share a fingerprint")
+	}
+	if Fingerprint(errPlain("timeout")) == Fingerprint(first) {
+		t.Error("Expected the root error type to change the fingerprint")
+	}
+}
+
+func TestSetNormalizers(t *testing.T) {
+	lower := func(msg string) string { return strings.ToLower(msg) }
+	previous := SetNormalizers(append([]Normalizer{lower}, DefaultNormalizers...)...)
+	defer SetNormalizers(previous...)
+
+	if len(previous) != len(DefaultNormalizers) {
+		t.Errorf("Expected the default normalizers to be returned, got %d", len(previous))
+	}
+	if norm := NormalizeMessage("Disk 3 FULL"); norm != "disk <n> full" {
+		t.Errorf("Expected custom normalizers to apply, got '%s'", norm)
+	}
+	if Fingerprint(errPlain("Disk FULL")) != Fingerprint(errPlain("disk full")) {
+		t.Error("Expected the fingerprint to use the custom normalizers")
+	}
+}
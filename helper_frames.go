@@ -0,0 +1,95 @@
+package serr
+
+import (
+	"path"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxHelperDepth bounds how many frames are skipped looking for the caller of helpers
+const maxHelperDepth = 16
+
+var (
+	helperFuncs    sync.Map // function name -> struct{}, registered by Helper
+	helperPatterns atomic.Pointer[[]string]
+	helpersInUse   atomic.Bool // keeps the single frame capture when no helpers exist
+)
+
+// Helper marks the calling function as an error helper, like testing.T.Helper.
+// Errors created or wrapped inside a helper get the location and function of the helper's caller.
+// Call it at the top of your own wrappers around New and Wrap
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	name := callerFrame(pcs[0]).frame.Function
+	if _, loaded := helperFuncs.LoadOrStore(name, struct{}{}); !loaded {
+		helpersInUse.Store(true)
+	}
+}
+
+// RegisterHelpers marks functions matching the patterns as error helpers, without changing them.
+// Patterns match fully qualified function names with path.Match syntax,
+// e.g. "github.com/acme/errs.Wrap" or "github.com/acme/errs.*" for the whole package
+func RegisterHelpers(patterns ...string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Wrap(err, "invalid helper pattern", "pattern", pattern)
+		}
+	}
+
+	for {
+		cur := helperPatterns.Load()
+		var next []string
+		if cur != nil {
+			next = append(next, *cur...)
+		}
+		next = append(next, patterns...)
+		if helperPatterns.CompareAndSwap(cur, &next) {
+			break
+		}
+	}
+	helpersInUse.Store(true)
+	return nil
+}
+
+// isHelper reports whether the function at pc is an error helper
+func isHelper(pc uintptr) bool {
+	name := callerFrame(pc).frame.Function
+	if _, ok := helperFuncs.Load(name); ok {
+		return true
+	}
+	if patterns := helperPatterns.Load(); patterns != nil {
+		for _, pattern := range *patterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callerPC returns the program counter of the frame at frameLevel, in runtime.Caller terms,
+// skipping any helper frames above it
+func callerPC(frameLevel int) (uintptr, bool) {
+	if !helpersInUse.Load() {
+		var pcs [1]uintptr
+		// to runtime.Callers, skip 0 is Callers itself, just as to runtime.Caller it is the function calling it
+		n := runtime.Callers(frameLevel+1, pcs[:])
+		return pcs[0], n > 0
+	}
+
+	var pcs [maxHelperDepth]uintptr
+	n := runtime.Callers(frameLevel+1, pcs[:])
+	if n == 0 {
+		return 0, false
+	}
+	for _, pc := range pcs[:n] {
+		if !isHelper(pc) {
+			return pc, true
+		}
+	}
+	return pcs[n-1], true
+}
@@ -0,0 +1,39 @@
+package serr
+
+import (
+	"testing"
+)
+
+// wrapDB is the kind of wrapper applications write around Wrap
+func wrapDB(err error, table string) error {
+	Helper()
+	return Wrap(err, "table", table)
+}
+
+func wrapViaPattern(err error) error {
+	return Wrap(err, "via", "pattern")
+}
+
+func TestHelperSkipsFrame(t *testing.T) {
+	err := wrapDB(New("no rows"), "users")
+
+	fns := err.(SErr).FieldsMapOfSliceOfAny()["function"]
+	if len(fns) != 2 || fns[0] != "rohanthewiz/serr.TestHelperSkipsFrame" || fns[1] != fns[0] {
+		t.Errorf("Expected both layers to report the test function, got %v", fns)
+	}
+}
+
+func TestRegisterHelpers(t *testing.T) {
+	if err := RegisterHelpers("github.com/rohanthewiz/serr.[a-"); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	if err := RegisterHelpers("github.com/rohanthewiz/serr.wrapVia*"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first function is that of New; the second is the layer added inside wrapViaPattern
+	err := wrapViaPattern(New("failed"))
+	if fn := err.(SErr).FieldsMapOfSliceOfAny()["function"][1]; fn != "rohanthewiz/serr.TestRegisterHelpers" {
+		t.Errorf("Expected the helper's caller, got '%v'", fn)
+	}
+}
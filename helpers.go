@@ -1,7 +1,6 @@
 package serr
 
 import (
-	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -65,7 +64,8 @@ func UserMsgFromErr(err error, alt ...string) (msg string) {
 	return
 }
 
-// FunctionLoc returns last two path tokens of caller.
+// FunctionLoc returns the location of the caller, by default the last two path tokens and the line.
+// See SetLocationFormat
 // optFuncLevel passes the function level to go back up.
 // The default is 1, referring to the caller of this function
 func FunctionLoc(optFuncLevel ...int) string {
@@ -74,12 +74,16 @@ func FunctionLoc(optFuncLevel ...int) string {
 		frameLevel = optFuncLevel[0]
 	}
 
-	_, fPath, line, ok := runtime.Caller(frameLevel)
+	pc, fPath, line, ok := runtime.Caller(frameLevel)
 	if !ok {
 		return ""
 	}
 
-	return fmt.Sprintf("%s:%d", LastNTokens(fPath, "/", 2), line)
+	frame := runtime.Frame{PC: pc, File: fPath, Line: line}
+	if fPtr := runtime.FuncForPC(pc); fPtr != nil {
+		frame.Function = fPtr.Name()
+	}
+	return currentLocationConfig().formatFor(frame.Function).location(frame)
 }
 
 // FunctionName returns the function name of the caller
@@ -97,7 +101,8 @@ func FunctionName(optFuncLevel ...int) (funcName string) {
 			return
 		}
 
-		return LastNTokens(fPtr.Name(), "/", 2)
+		frame := runtime.Frame{PC: pc, Function: fPtr.Name()}
+		return currentLocationConfig().formatFor(frame.Function).function(frame)
 	}
 	return
 }
@@ -37,6 +37,7 @@ func TestLastNTokens(t *testing.T) {
 }
 
 func TestStringFromErr(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
 	tests := []struct {
 		name    string
 		err     error
@@ -56,8 +57,8 @@ func TestStringFromErr(t *testing.T) {
 		{
 			name:    "SErr with message",
 			err:     NewSErr("serr message"),
-			want:    "serr message - Error: location[serr/helpers_test.go:58], function[rohanthewiz/serr.TestStringFromErr]",
-			wantAlt: "serr message - Error: function[rohanthewiz/serr.TestStringFromErr], location[serr/helpers_test.go:58]",
+			want:    "serr message - Error: location[serr/helpers_test.go:59], function[rohanthewiz/serr.TestStringFromErr], ref[01TESTREF]",
+			wantAlt: "serr message - Error: function[rohanthewiz/serr.TestStringFromErr], location[serr/helpers_test.go:59], ref[01TESTREF]",
 		},
 	}
 
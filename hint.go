@@ -0,0 +1,63 @@
+package serr
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	HintKey   = "hint"   // actionable next step for the user, e.g. "try --force"
+	DocURLKey = "docURL" // documentation about the error
+)
+
+// WithHint annotates err with an actionable next step, kept apart from the message.
+// Hints accumulate across wraps; see Hints
+func WithHint(err error, hint string) error {
+	if err == nil {
+		return nil
+	}
+	ser := NewSerrNoContext(err).Clone()
+	ser.AppendAttributes(HintKey, hint)
+	return ser
+}
+
+// WithDocURL annotates err with a link to its documentation.
+// It also becomes the problem+json type of the error
+func WithDocURL(err error, url string) error {
+	if err == nil {
+		return nil
+	}
+	ser := NewSerrNoContext(err).Clone()
+	ser.AppendAttributes(DocURLKey, url)
+	return ser
+}
+
+// Hints returns the hints of err's chain in the order they were attached, without duplicates
+func Hints(err error) (hints []string) {
+	var chain []SErr
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if ser, ok := e.(SErr); ok {
+			chain = append(chain, ser)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := len(chain) - 1; i >= 0; i-- { // innermost first
+		for _, val := range chain[i].FieldsMapOfSliceOfAny()[HintKey] {
+			hint := fmt.Sprintf("%v", val)
+			if !seen[hint] {
+				seen[hint] = true
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return
+}
+
+// DocURL returns the documentation URL of err, the outermost one if several were attached
+func DocURL(err error) string {
+	if val, ok := outermostAttr(err, DocURLKey); ok {
+		return fmt.Sprintf("%v", val)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package serr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHints(t *testing.T) {
+	if WithHint(nil, "unused") != nil || WithDocURL(nil, "unused") != nil {
+		t.Error("Expected annotating a nil error to return nil")
+	}
+
+	err := WithHint(New("lock held"), "wait for the other deploy to finish")
+	err = fmt.Errorf("deploy: %w", WithHint(Wrap(err, "app", "web"), "or run with --force"))
+	err = WithDocURL(WithHint(err, "or run with --force"), "https://docs.example.com/errors/lock-held")
+
+	hints := Hints(err)
+	if len(hints) != 2 || hints[0] != "wait for the other deploy to finish" || hints[1] != "or run with --force" {
+		t.Errorf("Expected hints in the order attached, without duplicates, got %v", hints)
+	}
+	if DocURL(err) != "https://docs.example.com/errors/lock-held" {
+		t.Errorf("Unexpected doc URL '%s'", DocURL(err))
+	}
+	if err.Error() != "deploy: lock held" {
+		t.Errorf("Expected hints to leave the message alone, got '%s'", err.Error())
+	}
+	if DocURL(New("plain")) != "" || Hints(errPlain("plain")) != nil {
+		t.Error("Expected no annotations on errors without them")
+	}
+}
+
+func TestHintsSurfaced(t *testing.T) {
+	err := WithDocURL(WithHint(New("config missing", CodeKey, "config_missing"), "run `app init`"), "https://docs.example.com/config")
+
+	if out := RenderString(err); !strings.Contains(out, "  help: run `app init`\n") || !strings.Contains(out, "  docs: https://docs.example.com/config\n") {
+		t.Errorf("Expected the renderer to show the hint and docs, got\n%s", out)
+	}
+
+	byts, _ := json.Marshal(err)
+	var doc struct {
+		Hints  []string
+		DocURL string
+	}
+	if jerr := json.Unmarshal(byts, &doc); jerr != nil || len(doc.Hints) != 1 || doc.DocURL != "https://docs.example.com/config" {
+		t.Errorf("Expected hints and docURL in the JSON, got %s", byts)
+	}
+
+	p := ProblemFromErr(err)
+	if p.Type != "https://docs.example.com/config" || len(p.Hints) != 1 {
+		t.Errorf("Expected the doc URL as problem type and the hints, got %+v", p)
+	}
+	if !err.(SErr).IsPublic(HintKey) {
+		t.Error("Expected hints to be public")
+	}
+}
@@ -0,0 +1,97 @@
+package serr
+
+import (
+	"errors"
+	"net/http"
+)
+
+const KindKey = "kind"             // error kind key
+const CodeKey = "code"             // error code key
+const HTTPStatusKey = "httpStatus" // HTTP status key
+
+// Kind classifies an error by what went wrong, independent of where it happened
+// It is stored on an SErr as the "kind" attribute
+type Kind string
+
+const (
+	KindUnknown      Kind = ""
+	KindInvalid      Kind = "invalid"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindTimeout      Kind = "timeout"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+)
+
+// HTTPStatus returns the HTTP status code conventionally used for the kind
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindInvalid:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// KindOf returns the kind of the first SErr in err's chain.
+// If the kind was set more than once, the outermost value wins
+func KindOf(err error) Kind {
+	if val, ok := outermostAttr(err, KindKey); ok {
+		switch k := val.(type) {
+		case Kind:
+			return k
+		case string:
+			return Kind(k)
+		}
+	}
+	return KindUnknown
+}
+
+// CodeOf returns the error code of the first SErr in err's chain, if any
+func CodeOf(err error) string {
+	if val, ok := outermostAttr(err, CodeKey); ok {
+		if code, ok := val.(string); ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// HTTPStatusOf returns the HTTP status set on err,
+// or the status implied by its kind if none was set explicitly
+func HTTPStatusOf(err error) int {
+	if val, ok := outermostAttr(err, HTTPStatusKey); ok {
+		if status, ok := val.(int); ok {
+			return status
+		}
+	}
+	return KindOf(err).HTTPStatus()
+}
+
+// outermostAttr returns the most recently added value of key in the first SErr of err's chain
+func outermostAttr(err error, key string) (val any, ok bool) {
+	var ser SErr
+	if !errors.As(err, &ser) {
+		return nil, false
+	}
+
+	for i := len(ser.fields) - 2; i >= 0; i -= 2 {
+		if k, isStr := ser.fields[i].(string); isStr && k == key {
+			return ser.fields[i+1], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,255 @@
+package serr
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// PathStyle selects how the file path of a location is shortened
+type PathStyle int
+
+const (
+	// PathLastTokens keeps the last LocationFormat.PathTokens elements of the path, e.g. "serr/serr.go"
+	PathLastTokens PathStyle = iota
+	// PathModuleRelative makes paths in the main module relative to its root, e.g. "services/users/handlers.go".
+	// Paths in other modules are prefixed with their module path
+	PathModuleRelative
+	// PathTrimmed removes the GOROOT, GOPATH and module cache prefixes from the path
+	PathTrimmed
+	// PathFull keeps the path as compiled into the binary
+	PathFull
+)
+
+// CallerFields selects which caller attributes are recorded on each wrap
+type CallerFields int
+
+const (
+	LocationAndFunction CallerFields = iota // the default
+	LocationOnly                            // file and line only
+	FunctionOnly                            // function name only
+)
+
+// LocationFormat configures how the "location" and "function" attributes are rendered.
+// The zero value is the historical format: "serr/serr.go:42" and "rohanthewiz/serr.New"
+type LocationFormat struct {
+	Path PathStyle
+	// PathTokens is the number of path elements kept by PathLastTokens. The default is 2
+	PathTokens int
+	// FuncTokens is the number of slash separated elements kept of function names. The default is 2.
+	// A negative value keeps the full import path
+	FuncTokens int
+	Fields     CallerFields
+	// FormatLocation and FormatFunction, when set, replace the built-in rendering
+	FormatLocation func(frame runtime.Frame) string
+	FormatFunction func(frame runtime.Frame) string
+}
+
+// location renders the file and line of frame
+func (f LocationFormat) location(frame runtime.Frame) string {
+	if f.FormatLocation != nil {
+		return f.FormatLocation(frame)
+	}
+	if frame.File == "" {
+		return ""
+	}
+
+	var file string
+	switch f.Path {
+	case PathModuleRelative:
+		file = moduleRelativePath(frame)
+	case PathTrimmed:
+		file = trimGoPaths(frame.File)
+	case PathFull:
+		file = frame.File
+	default:
+		n := f.PathTokens
+		if n <= 0 {
+			n = 2
+		}
+		file = LastNTokens(frame.File, "/", n)
+	}
+	return file + ":" + strconv.Itoa(frame.Line)
+}
+
+// function renders the function name of frame
+func (f LocationFormat) function(frame runtime.Frame) string {
+	if f.FormatFunction != nil {
+		return f.FormatFunction(frame)
+	}
+	switch {
+	case frame.Function == "" || f.FuncTokens < 0:
+		return frame.Function
+	case f.FuncTokens == 0:
+		return LastNTokens(frame.Function, "/", 2)
+	}
+	return LastNTokens(frame.Function, "/", f.FuncTokens)
+}
+
+// locationConfig is the global format and the per-package overrides.
+// It is replaced as a whole on each change, so rendered frames can tell they are stale
+type locationConfig struct {
+	global   LocationFormat
+	packages map[string]LocationFormat // by import path
+}
+
+var (
+	locationCfg   atomic.Pointer[locationConfig]
+	locationCfgMu sync.Mutex // serializes updates
+)
+
+func currentLocationConfig() *locationConfig {
+	if cfg := locationCfg.Load(); cfg != nil {
+		return cfg
+	}
+	locationCfg.CompareAndSwap(nil, &locationConfig{})
+	return locationCfg.Load()
+}
+
+// formatFor returns the format for a function, by its fully qualified name.
+// The package override with the longest matching import path wins
+func (cfg *locationConfig) formatFor(function string) LocationFormat {
+	if len(cfg.packages) == 0 {
+		return cfg.global
+	}
+
+	pkg := funcPackagePath(function)
+	format, matched := cfg.global, ""
+	for prefix, f := range cfg.packages {
+		if (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) && len(prefix) > len(matched) {
+			format, matched = f, prefix
+		}
+	}
+	return format
+}
+
+// updateLocationConfig applies fn to a copy of the current config
+func updateLocationConfig(fn func(cfg *locationConfig)) {
+	locationCfgMu.Lock()
+	defer locationCfgMu.Unlock()
+
+	cur := currentLocationConfig()
+	next := &locationConfig{global: cur.global, packages: make(map[string]LocationFormat, len(cur.packages))}
+	for pkg, f := range cur.packages {
+		next.packages[pkg] = f
+	}
+	fn(next)
+	locationCfg.Store(next)
+}
+
+// SetLocationFormat sets the location format of all packages without their own format.
+// Errors already created are rendered with the new format too
+func SetLocationFormat(format LocationFormat) {
+	updateLocationConfig(func(cfg *locationConfig) {
+		cfg.global = format
+	})
+}
+
+// SetPackageLocationFormat sets the location format of errors created in the package
+// with the given import path and in its subpackages, e.g. "github.com/acme/mono/billing"
+func SetPackageLocationFormat(pkgPath string, format LocationFormat) {
+	updateLocationConfig(func(cfg *locationConfig) {
+		cfg.packages[strings.TrimSuffix(pkgPath, "/")] = format
+	})
+}
+
+// ResetLocationFormats restores the default location format and removes all package formats
+func ResetLocationFormats() {
+	updateLocationConfig(func(cfg *locationConfig) {
+		*cfg = locationConfig{}
+	})
+}
+
+// funcPackagePath returns the import path of the package of a fully qualified function name,
+// e.g. "github.com/rohanthewiz/serr" for "github.com/rohanthewiz/serr.SErr.Error"
+func funcPackagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// buildModules holds the module paths of the running binary, the main module first
+var buildModules = sync.OnceValue(func() (mods []string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	mods = append(mods, info.Main.Path)
+	for _, dep := range info.Deps {
+		mods = append(mods, dep.Path)
+	}
+	return
+})
+
+// mainModuleRoot is the directory of the main module, learned from the first
+// of its non-main packages to be rendered. It lets files of package main be made relative too
+var mainModuleRoot atomic.Value // string
+
+// moduleRelativePath returns the path of frame's file relative to its module
+func moduleRelativePath(frame runtime.Frame) string {
+	file := filepath.ToSlash(frame.File)
+	pkg := strings.TrimSuffix(funcPackagePath(frame.Function), "_test")
+
+	mods := buildModules()
+	module := ""
+	for _, mod := range mods {
+		if mod != "" && (pkg == mod || strings.HasPrefix(pkg, mod+"/")) && len(mod) > len(module) {
+			module = mod
+		}
+	}
+
+	switch {
+	case module != "":
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg, module), "/")
+		if module != mods[0] {
+			return path.Join(module, rel, path.Base(file))
+		}
+		if dir := path.Dir(file); rel == "" || strings.HasSuffix(dir, "/"+rel) {
+			mainModuleRoot.CompareAndSwap(nil, strings.TrimSuffix(strings.TrimSuffix(dir, rel), "/"))
+		}
+		return path.Join(rel, path.Base(file))
+
+	case pkg == "main":
+		if root, _ := mainModuleRoot.Load().(string); root != "" && strings.HasPrefix(file, root+"/") {
+			return strings.TrimPrefix(file, root+"/")
+		}
+	}
+	return trimGoPaths(file)
+}
+
+// goPathPrefixes are the directories trimmed by PathTrimmed, longest first
+var goPathPrefixes = sync.OnceValue(func() (prefixes []string) {
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	for _, dir := range filepath.SplitList(gopath) {
+		dir = filepath.ToSlash(dir)
+		prefixes = append(prefixes, dir+"/pkg/mod/", dir+"/src/")
+	}
+	if goroot := runtime.GOROOT(); goroot != "" {
+		prefixes = append(prefixes, filepath.ToSlash(goroot)+"/src/")
+	}
+	return
+})
+
+// trimGoPaths removes the GOPATH, module cache or GOROOT prefix from a file path
+func trimGoPaths(file string) string {
+	file = filepath.ToSlash(file)
+	for _, prefix := range goPathPrefixes() {
+		if strings.HasPrefix(file, prefix) {
+			return strings.TrimPrefix(file, prefix)
+		}
+	}
+	return file
+}
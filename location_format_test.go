@@ -0,0 +1,100 @@
+package serr
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLocationFormatPaths(t *testing.T) {
+	defer ResetLocationFormats()
+
+	tests := []struct {
+		name     string
+		format   LocationFormat
+		location string // expected prefix
+		function string
+	}{
+		{"default", LocationFormat{}, "serr/location_format_test.go:", "rohanthewiz/serr.TestLocationFormatPaths"},
+		{"one token", LocationFormat{PathTokens: 1, FuncTokens: 1}, "location_format_test.go:", "serr.TestLocationFormatPaths"},
+		{"module relative", LocationFormat{Path: PathModuleRelative, FuncTokens: -1}, "location_format_test.go:",
+			"github.com/rohanthewiz/serr.TestLocationFormatPaths"},
+		{"custom", LocationFormat{
+			FormatLocation: func(frame runtime.Frame) string { return "at " + frame.Function },
+			FormatFunction: func(frame runtime.Frame) string { return "fn" },
+		}, "at github.com/rohanthewiz/serr.TestLocationFormatPaths", "fn"},
+	}
+
+	for _, tt := range tests {
+		SetLocationFormat(tt.format)
+		mp := New("formatted").(SErr).FieldsMap()
+		if !strings.HasPrefix(mp["location"], tt.location) {
+			t.Errorf("%s: expected location starting with '%s', got '%s'", tt.name, tt.location, mp["location"])
+		}
+		if mp["function"] != tt.function {
+			t.Errorf("%s: expected function '%s', got '%s'", tt.name, tt.function, mp["function"])
+		}
+	}
+}
+
+func TestLocationFormatAppliesToExistingErrors(t *testing.T) {
+	defer ResetLocationFormats()
+
+	err := New("rendered later").(SErr)
+	SetLocationFormat(LocationFormat{FuncTokens: 1})
+	if fn := err.FieldsMap()["function"]; fn != "serr.TestLocationFormatAppliesToExistingErrors" {
+		t.Errorf("Expected the new format to apply, got '%s'", fn)
+	}
+	if fn := FunctionName(); fn != "serr.TestLocationFormatAppliesToExistingErrors" {
+		t.Errorf("Expected FunctionName to use the format, got '%s'", fn)
+	}
+}
+
+func TestPackageLocationFormat(t *testing.T) {
+	defer ResetLocationFormats()
+
+	SetLocationFormat(LocationFormat{Fields: LocationOnly})
+	if _, ok := New("global").(SErr).GetAttribute("function"); ok {
+		t.Error("Expected no function attribute with LocationOnly")
+	}
+
+	SetPackageLocationFormat("github.com/rohanthewiz", LocationFormat{Fields: FunctionOnly})
+	SetPackageLocationFormat("github.com/rohanthewiz/serr/", LocationFormat{Fields: FunctionOnly, FuncTokens: 1})
+	ser := New("per package").(SErr)
+	if _, ok := ser.GetAttribute("location"); ok {
+		t.Error("Expected no location attribute with FunctionOnly")
+	}
+	if fn := ser.FieldsMap()["function"]; fn != "serr.TestPackageLocationFormat" {
+		t.Errorf("Expected the most specific package format, got '%s'", fn)
+	}
+
+	// User messages still find their layer without locations
+	ser = Wrap(ser, UserMsgKey, "Try again").(SErr)
+	if msgs := ser.UserMessages(); len(msgs) != 1 || msgs[0].Function != "serr.TestPackageLocationFormat" {
+		t.Errorf("Unexpected user messages %+v", msgs)
+	}
+}
+
+func TestTrimGoPaths(t *testing.T) {
+	gopath := goPathPrefixes()[0] // the module cache of the first GOPATH entry
+	if got := trimGoPaths(gopath + "github.com/lib/pq@v1.10.9/conn.go"); got != "github.com/lib/pq@v1.10.9/conn.go" {
+		t.Errorf("Expected the module cache to be trimmed, got '%s'", got)
+	}
+	if got := trimGoPaths("/srv/app/main.go"); got != "/srv/app/main.go" {
+		t.Errorf("Expected other paths to be kept, got '%s'", got)
+	}
+}
+
+func TestFuncPackagePath(t *testing.T) {
+	tests := map[string]string{
+		"github.com/rohanthewiz/serr.SErr.Error": "github.com/rohanthewiz/serr",
+		"github.com/rohanthewiz/serr.New.func1":  "github.com/rohanthewiz/serr",
+		"main.main":                              "main",
+		"github.com/acme/mono/v2/users.(*H).Get": "github.com/acme/mono/v2/users",
+	}
+	for fn, expected := range tests {
+		if got := funcPackagePath(fn); got != expected {
+			t.Errorf("Expected package of '%s' to be '%s', got '%s'", fn, expected, got)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package serr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const ExitCodeKey = "exitCode" // process exit code attribute
+
+// Exit codes of sysexits.h
+const (
+	ExitOK          = 0
+	ExitFailure     = 1  // generic failure, for errors without a kind
+	ExitUsage       = 64 // command line usage error
+	ExitDataErr     = 65 // data format error
+	ExitNoInput     = 66 // cannot open input
+	ExitNoUser      = 67 // addressee unknown
+	ExitUnavailable = 69 // service unavailable
+	ExitSoftware    = 70 // internal software error
+	ExitOSErr       = 71 // system error
+	ExitCantCreate  = 73 // can't create (user) output file
+	ExitIOErr       = 74 // input/output error
+	ExitTempFail    = 75 // temp failure; user is invited to retry
+	ExitProtocol    = 76 // remote error in protocol
+	ExitNoPerm      = 77 // permission denied
+	ExitConfig      = 78 // configuration error
+)
+
+// KindExitCodes maps error kinds onto exit codes for ExitCodeOf. Kinds not listed exit with ExitFailure
+var KindExitCodes = map[Kind]int{
+	KindInvalid:      ExitDataErr,
+	KindNotFound:     ExitNoInput,
+	KindConflict:     ExitTempFail,
+	KindUnauthorized: ExitNoPerm,
+	KindForbidden:    ExitNoPerm,
+	KindTimeout:      ExitTempFail,
+	KindUnavailable:  ExitUnavailable,
+	KindInternal:     ExitSoftware,
+}
+
+// OutputEnv selects the error output of Main: "json" for a JSON object, anything else for Render
+const OutputEnv = "SERR_OUTPUT"
+
+// WithExitCode annotates err with the exit code Main should use for it
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	ser := NewSerrNoContext(err).Clone()
+	ser.AppendAttributes(ExitCodeKey, code)
+	return ser
+}
+
+// ExitCodeOf returns the exit code for err: ExitOK for nil, the outermost exit code attribute,
+// or else the code of its kind in KindExitCodes
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if val, ok := outermostAttr(err, ExitCodeKey); ok {
+		switch v := val.(type) {
+		case int:
+			return v
+		case string:
+			if code, cerr := strconv.Atoi(v); cerr == nil {
+				return code
+			}
+		}
+	}
+	if code, ok := KindExitCodes[KindOf(err)]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// MainOptions configures Main. Zero values select the defaults
+type MainOptions struct {
+	// JSON writes the error as a JSON object instead of rendering it. The OutputEnv variable also selects it
+	JSON bool
+	// Stderr receives the error output, default os.Stderr
+	Stderr io.Writer
+	// Signals cancel the context, default SIGINT and SIGTERM. A second signal exits immediately
+	Signals []os.Signal
+	// ReportTimeout bounds how long buffered reports are flushed on exit, default 5s
+	ReportTimeout time.Duration
+
+	exit func(code int) // overridden in tests
+}
+
+// Main runs a command-line program and exits with its outcome:
+//   - run gets a context cancelled by SIGINT or SIGTERM
+//   - panics are recovered into errors of kind internal
+//   - an error is reported to the default dispatcher, if any, and written to stderr
+//   - the exit code comes from ExitCodeOf, or is 128 + the signal number when interrupted
+func Main(run func(ctx context.Context) error, opts ...MainOptions) {
+	var o MainOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	exit := o.exit
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(RunMain(run, opts...))
+}
+
+// RunMain runs the program as Main does, returning the exit code instead of exiting
+func RunMain(run func(ctx context.Context) error, opts ...MainOptions) int {
+	var o MainOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Stderr == nil {
+		o.Stderr = os.Stderr
+	}
+	if len(o.Signals) == 0 {
+		o.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if o.ReportTimeout <= 0 {
+		o.ReportTimeout = 5 * time.Second
+	}
+	if o.exit == nil {
+		o.exit = os.Exit
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, o.Signals...)
+	defer signal.Stop(sigs)
+
+	received := make(chan os.Signal, 1)
+	go func() {
+		select {
+		case sig := <-sigs:
+			received <- sig
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case sig := <-sigs:
+			o.exit(signalExitCode(sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	err := runRecovered(ctx, run)
+	code := ExitCodeOf(err)
+	select {
+	case sig := <-received:
+		if err == nil || errors.Is(err, context.Canceled) {
+			code = signalExitCode(sig)
+		}
+	default:
+	}
+	if err == nil {
+		return code
+	}
+
+	if d := DefaultDispatcher(); d != nil {
+		d.Report(err)
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), o.ReportTimeout)
+		_ = d.Flush(flushCtx)
+		cancelFlush()
+	}
+
+	if o.JSON || os.Getenv(OutputEnv) == "json" {
+		writeJSONError(o.Stderr, err, code)
+	} else {
+		_ = Render(o.Stderr, err)
+	}
+	return code
+}
+
+// runRecovered calls run, turning a panic into an error
+func runRecovered(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fields := []string{KindKey, string(KindInternal), "stack", string(debug.Stack())}
+			var ser SErr
+			if rErr, ok := r.(error); ok {
+				ser = WrapAsSErr(rErr, append([]string{"panic", "true"}, fields...)...)
+			} else {
+				ser = NewSErr(fmt.Sprintf("panic: %v", r), fields...)
+			}
+			ser.SetSeverity(SeverityCritical)
+			err = ser
+		}
+	}()
+	return run(ctx)
+}
+
+// writeJSONError writes err as one JSON object, with its exit code
+func writeJSONError(w io.Writer, err error, code int) {
+	var ser SErr
+	if !errors.As(err, &ser) {
+		ser = NewSerrNoContext(err)
+	}
+	byts, jerr := json.Marshal(ser)
+	if jerr != nil {
+		byts, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+
+	var obj map[string]any
+	if json.Unmarshal(byts, &obj) == nil {
+		obj["error"] = err.Error() // the full message, including non-SErr wrappers
+		obj["exitCode"] = code
+		byts, _ = json.Marshal(obj)
+	}
+	_, _ = w.Write(append(byts, '\n'))
+}
+
+// signalExitCode follows the shell convention of 128 + the signal number
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return ExitFailure
+}
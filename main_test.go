@@ -0,0 +1,105 @@
+package serr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExitCodeOf(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{errors.New("plain"), ExitFailure},
+		{New("bad input", KindKey, string(KindInvalid)), ExitDataErr},
+		{New("no file", KindKey, string(KindNotFound)), ExitNoInput},
+		{New("denied", KindKey, string(KindForbidden)), ExitNoPerm},
+		{New("busy", KindKey, string(KindUnavailable)), ExitUnavailable},
+		{WithExitCode(New("bad flag", KindKey, string(KindInvalid)), ExitUsage), ExitUsage},
+		{fmt.Errorf("outer: %w", WithExitCode(errors.New("config"), ExitConfig)), ExitConfig},
+	}
+	for _, c := range cases {
+		if code := ExitCodeOf(c.err); code != c.expected {
+			t.Errorf("Expected exit code %d for %v, got %d", c.expected, c.err, code)
+		}
+	}
+	if WithExitCode(nil, ExitUsage) != nil {
+		t.Error("Expected WithExitCode of nil to be nil")
+	}
+}
+
+func TestRunMain(t *testing.T) {
+	var stderr bytes.Buffer
+	opts := MainOptions{Stderr: &stderr}
+
+	if code := RunMain(func(ctx context.Context) error { return nil }, opts); code != ExitOK || stderr.Len() > 0 {
+		t.Errorf("Expected a clean exit, got %d and %q", code, stderr.String())
+	}
+
+	code := RunMain(func(ctx context.Context) error {
+		return New("db down", KindKey, string(KindUnavailable))
+	}, opts)
+	if code != ExitUnavailable {
+		t.Errorf("Expected exit code %d, got %d", ExitUnavailable, code)
+	}
+	if !strings.HasPrefix(stderr.String(), "error: db down\n") || !strings.Contains(stderr.String(), "--> ") {
+		t.Errorf("Expected the rendered error on stderr, got\n%s", stderr.String())
+	}
+}
+
+func TestRunMainJSON(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+	var stderr bytes.Buffer
+
+	code := RunMain(func(ctx context.Context) error {
+		return fmt.Errorf("run: %w", WithHint(New("no config", "path", "/etc/app.toml"), "create it with app init"))
+	}, MainOptions{Stderr: &stderr, JSON: true})
+
+	var out struct {
+		Error    string
+		Ref      string
+		Hints    []string
+		ExitCode int
+		Fields   map[string]any
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &out); err != nil {
+		t.Fatalf("Expected a JSON object, got %q: %v", stderr.String(), err)
+	}
+	if out.Error != "run: no config" || out.Ref != "01TESTREF" || out.ExitCode != code || code != ExitFailure {
+		t.Errorf("Unexpected JSON error %+v", out)
+	}
+	if len(out.Hints) != 1 || out.Fields["path"] != "/etc/app.toml" {
+		t.Errorf("Expected hints and fields in %s", stderr.String())
+	}
+}
+
+func TestRunMainPanic(t *testing.T) {
+	var stderr bytes.Buffer
+	code := RunMain(func(ctx context.Context) error {
+		panic("boom")
+	}, MainOptions{Stderr: &stderr})
+
+	if code != ExitSoftware {
+		t.Errorf("Expected exit code %d for a panic, got %d", ExitSoftware, code)
+	}
+	if !strings.HasPrefix(stderr.String(), "critical: panic: boom\n") {
+		t.Errorf("Expected the panic on stderr, got\n%s", stderr.String())
+	}
+}
+
+func TestMainExits(t *testing.T) {
+	var exited int
+	Main(func(ctx context.Context) error {
+		return WithExitCode(New("usage"), ExitUsage)
+	}, MainOptions{Stderr: &bytes.Buffer{}, exit: func(code int) { exited = code }})
+
+	if exited != ExitUsage {
+		t.Errorf("Expected Main to exit with %d, got %d", ExitUsage, exited)
+	}
+}
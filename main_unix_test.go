@@ -0,0 +1,32 @@
+//go:build unix
+
+package serr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunMainSignal(t *testing.T) {
+	var stderr bytes.Buffer
+	code := RunMain(func(ctx context.Context) error {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("context not cancelled")
+		}
+	}, MainOptions{Stderr: &stderr, Signals: []os.Signal{syscall.SIGUSR1}})
+
+	if expected := 128 + int(syscall.SIGUSR1); code != expected {
+		t.Errorf("Expected exit code %d, got %d (%s)", expected, code, stderr.String())
+	}
+}
@@ -0,0 +1,122 @@
+package serr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NewN returns a new SErr from a format containing named placeholders.
+// Each placeholder is replaced by the next argument, which is also stored as an attribute
+// under the placeholder's name, so the values remain queryable by structured loggers.
+// A placeholder may carry a fmt verb, e.g. {ratio:%.2f}; the default is %v.
+// Use {{ and }} for literal braces.
+// Example:
+//
+//	err := serr.NewN("failed to read {file}", "config.yaml")
+//	// err.Error() => "failed to read config.yaml"; attributes: file[config.yaml]
+func NewN(format string, args ...any) error {
+	msg, attrs := formatNamed(format, args...)
+	se := SErr{err: errors.New(msg), fields: attrs}
+	return se.newSErr()
+}
+
+// WrapN wraps an existing error with a msg built from a format containing named placeholders.
+// As with NewN, each argument is also stored as an attribute under its placeholder name.
+// Example:
+//
+//	err = serr.WrapN(err, "loading {user} profile", userID)
+//	// attributes: msg[loading 42 profile], user[42]
+func WrapN(err error, format string, args ...any) error {
+	if err == nil {
+		fmt.Println("SErr: Not wrapping a nil error", "callerLocation:", FunctionLoc(FrameLevels.FrameLevel2),
+			"callerName:", FunctionName(FrameLevels.FrameLevel2))
+		return nil
+	}
+
+	msg, attrs := formatNamed(format, args...)
+
+	se := withAttributes(NewSerrNoContext(err), append([]any{"msg", msg}, attrs...)...)
+	return se.newSErr()
+}
+
+// formatNamed renders a named placeholder format, returning the message
+// and the placeholder names paired with their argument values.
+// Missing arguments render like fmt does, as %!name(MISSING)
+func formatNamed(format string, args ...any) (msg string, attrs []any) {
+	argIdx := 0
+
+	msg = replaceNamed(format, func(name, verb, _ string) string {
+		if argIdx >= len(args) {
+			return "%!" + name + "(MISSING)"
+		}
+		arg := args[argIdx]
+		argIdx++
+
+		attrs = append(attrs, name, arg)
+		return fmt.Sprintf(verb, arg)
+	})
+
+	for ; argIdx < len(args); argIdx++ {
+		msg += fmt.Sprintf("%%!(EXTRA %v)", args[argIdx])
+	}
+	return msg, attrs
+}
+
+// expandNamed fills the placeholders of format from name, value pairs as returned by formatNamed.
+// Placeholders without a value are left as is
+func expandNamed(format string, attrs []any) string {
+	values := map[string]any{}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if name, ok := attrs[i].(string); ok {
+			values[name] = attrs[i+1]
+		}
+	}
+
+	return replaceNamed(format, func(name, verb, placeholder string) string {
+		if val, ok := values[name]; ok {
+			return fmt.Sprintf(verb, val)
+		}
+		return placeholder
+	})
+}
+
+// replaceNamed substitutes each {name} or {name:verb} placeholder in format
+// with the result of replace. {{ and }} are written as literal braces
+func replaceNamed(format string, replace func(name, verb, placeholder string) string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if (c == '{' || c == '}') && i+1 < len(format) && format[i+1] == c {
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(format[i:], '}')
+		if c != '{' || end < 0 {
+			sb.WriteByte(c)
+			continue
+		}
+
+		placeholder := format[i : i+end+1]
+		name, verb := placeholder[1:end], "%v"
+		if idx := strings.IndexByte(name, ':'); idx >= 0 {
+			name, verb = name[:idx], name[idx+1:]
+		}
+		i += end
+
+		sb.WriteString(replace(name, verb, placeholder))
+	}
+	return sb.String()
+}
+
+// PlaceholderNames returns the names of the placeholders in a named placeholder format, in order
+func PlaceholderNames(format string) (names []string) {
+	replaceNamed(format, func(name, _, _ string) string {
+		names = append(names, name)
+		return ""
+	})
+	return
+}
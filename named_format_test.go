@@ -0,0 +1,135 @@
+package serr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatNamed(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		args      []any
+		wantMsg   string
+		wantAttrs []any
+	}{
+		{
+			name:      "Single placeholder",
+			format:    "failed to read {file}",
+			args:      []any{"config.yaml"},
+			wantMsg:   "failed to read config.yaml",
+			wantAttrs: []any{"file", "config.yaml"},
+		},
+		{
+			name:      "Verb and multiple placeholders",
+			format:    "{host}:{port} at {load:%.1f}",
+			args:      []any{"localhost", 8080, 0.456},
+			wantMsg:   "localhost:8080 at 0.5",
+			wantAttrs: []any{"host", "localhost", "port", 8080, "load", 0.456},
+		},
+		{
+			name:    "Literal braces",
+			format:  "{{not a placeholder}}",
+			wantMsg: "{not a placeholder}",
+		},
+		{
+			name:      "Missing and extra args",
+			format:    "{a} {b}",
+			args:      []any{1},
+			wantMsg:   "1 %!b(MISSING)",
+			wantAttrs: []any{"a", 1},
+		},
+		{
+			name:      "Extra args",
+			format:    "{a}",
+			args:      []any{1, 2},
+			wantMsg:   "1%!(EXTRA 2)",
+			wantAttrs: []any{"a", 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, attrs := formatNamed(tt.format, tt.args...)
+			if msg != tt.wantMsg {
+				t.Errorf("Expected message %q, got %q", tt.wantMsg, msg)
+			}
+			if len(attrs) != len(tt.wantAttrs) {
+				t.Fatalf("Expected attributes %v, got %v", tt.wantAttrs, attrs)
+			}
+			for i := range attrs {
+				if attrs[i] != tt.wantAttrs[i] {
+					t.Errorf("Expected attributes %v, got %v", tt.wantAttrs, attrs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewN(t *testing.T) {
+	err := NewN("failed to read {file} after {tries} tries", "config.yaml", 3)
+
+	const expectedErr = "failed to read config.yaml after 3 tries"
+	if err.Error() != expectedErr {
+		t.Errorf("Expected error message '%s', got '%s'", expectedErr, err.Error())
+	}
+
+	se, ok := err.(SErr)
+	if !ok {
+		t.Fatal("NewN() should return a SErr type")
+	}
+
+	if val, ok := se.GetAttribute("tries"); !ok || val != 3 {
+		t.Errorf("Expected attribute 'tries' to be int 3, got %#v", val)
+	}
+	mp := se.FieldsMap()
+	if mp["file"] != "config.yaml" {
+		t.Errorf("Expected attribute 'file' to be 'config.yaml', got '%s'", mp["file"])
+	}
+	if loc := mp["location"]; !strings.Contains(loc, "named_format_test.go:") {
+		t.Errorf("Expected 'location' field to point to the caller, got '%s'", loc)
+	}
+	if fn := mp["function"]; fn != "rohanthewiz/serr.TestNewN" {
+		t.Errorf("Expected 'function' field to be the caller, got '%s'", fn)
+	}
+}
+
+func TestWrapN(t *testing.T) {
+	if got := WrapN(nil, "ignored {x}", 1); got != nil {
+		t.Error("WrapN(nil, ...) should return nil")
+	}
+
+	base := NewSErr("base error", "layer", "inner")
+	base.AppendPublicAttributes("tenant", "acme")
+	err := WrapN(base, "loading profile for {user}", 42)
+
+	se, ok := err.(SErr)
+	if !ok {
+		t.Fatal("WrapN should return an error containing a concrete SErr type")
+	}
+	if se.Error() != "base error" {
+		t.Errorf("Expected wrapped error 'base error', got '%s'", se.Error())
+	}
+
+	mp := se.FieldsMap()
+	if mp["msg"] != "loading profile for 42" {
+		t.Errorf("Expected msg 'loading profile for 42', got '%s'", mp["msg"])
+	}
+	if mp["user"] != "42" {
+		t.Errorf("Expected attribute 'user' to be '42', got '%s'", mp["user"])
+	}
+	if mp["layer"] != "inner" {
+		t.Errorf("Expected inner attribute 'layer' to be preserved, got '%s'", mp["layer"])
+	}
+	if se.Ref() != base.Ref() {
+		t.Error("Expected the reference ID of the wrapped SErr to be kept")
+	}
+	if !se.IsPublic("tenant") {
+		t.Error("Expected the public keys of the wrapped SErr to be kept")
+	}
+	if !errors.Is(err, se.GetError()) {
+		t.Error("Expected wrapped error to match with errors.Is")
+	}
+}
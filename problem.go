@@ -0,0 +1,69 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 9457 (formerly 7807) problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem holds RFC 9457 problem details for an HTTP API error response.
+// Only public information is included: the user message, code, kind, reference ID, hints
+// and, for validation failures, the field problems. Internal attributes never are
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extension members
+	Code   string       `json:"code,omitempty"`
+	Kind   Kind         `json:"kind,omitempty"`
+	Ref    string       `json:"ref,omitempty"`
+	Hints  []string     `json:"hints,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// ProblemFromErr builds problem details from err.
+// The status is that of HTTPStatusOf, the detail is the user message
+// and the type is the documentation URL of the error, if it has one
+func ProblemFromErr(err error) Problem {
+	status := HTTPStatusOf(err)
+
+	var detail string
+	var ser SErr
+	if errors.As(err, &ser) {
+		detail, _ = ser.UserMsg()
+	}
+
+	typ := DocURL(err)
+	if typ == "" {
+		typ = "about:blank"
+	}
+
+	p := Problem{
+		Type:   typ,
+		Status: status,
+		Title:  http.StatusText(status),
+		Detail: detail,
+		Code:   CodeOf(err),
+		Kind:   KindOf(err),
+		Ref:    RefFromErr(err),
+		Hints:  Hints(err),
+	}
+	if ve, ok := ValidationErrorsFrom(err); ok {
+		p.Errors = ve.Fields
+	}
+	return p
+}
+
+// WriteProblem writes err to an HTTP response as problem details
+func WriteProblem(w http.ResponseWriter, err error) error {
+	p := ProblemFromErr(err)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
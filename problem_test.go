@@ -0,0 +1,63 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProblemFromErr(t *testing.T) {
+	def := Define("user_not_found", "user {id} not found", KindNotFound, WithUserMsg("We could not find that user", Severity.Warn))
+	err := Wrap(def.New(42), "sql", "SELECT * FROM users")
+
+	p := ProblemFromErr(err)
+	if p.Status != http.StatusNotFound || p.Title != "Not Found" || p.Type != "about:blank" {
+		t.Errorf("Unexpected status, title or type: %+v", p)
+	}
+	if p.Detail != "We could not find that user" || p.Code != "user_not_found" || p.Kind != KindNotFound {
+		t.Errorf("Unexpected detail, code or kind: %+v", p)
+	}
+	if p.Ref == "" || p.Ref != RefFromErr(err) {
+		t.Errorf("Expected the reference ID, got '%s'", p.Ref)
+	}
+
+	// A plain error is an internal server error without details
+	p = ProblemFromErr(errors.New("connection refused"))
+	if p.Status != http.StatusInternalServerError || p.Detail != "" {
+		t.Errorf("Unexpected problem for a plain error: %+v", p)
+	}
+}
+
+func TestWriteProblemValidation(t *testing.T) {
+	var ve ValidationErrors
+	ve.Add("/email", "required", "Email is required")
+
+	rec := httptest.NewRecorder()
+	if err := WriteProblem(rec, ve.Err()); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Expected content type %s, got %s", ProblemContentType, ct)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "location") || strings.Contains(body, "validation failed") {
+		t.Errorf("Expected no internal details in the problem, got %s", body)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	errs, ok := decoded["errors"].([]any)
+	if !ok || len(errs) != 1 || errs[0].(map[string]any)["path"] != "/email" {
+		t.Errorf("Expected an errors array with the field problem, got %s", body)
+	}
+}
@@ -0,0 +1,96 @@
+package serr
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Attributes are internal by default: they may hold locations, function names, SQL or hostnames.
+// Public attributes are safe to show users or external API callers.
+// A key is public if it is registered with RegisterPublicKeys,
+// or it was added to the error with AppendPublicAttributes.
+var publicKeys = struct {
+	sync.RWMutex
+	keys map[string]bool
+}{keys: map[string]bool{
+	UserMsgKey: true, UserMsgSeverityKey: true, CodeKey: true, KindKey: true, HTTPStatusKey: true,
+	HintKey: true, DocURLKey: true,
+}}
+
+// RegisterPublicKeys marks attribute keys as public for all errors
+func RegisterPublicKeys(keys ...string) {
+	publicKeys.Lock()
+	defer publicKeys.Unlock()
+
+	for _, key := range keys {
+		publicKeys.keys[key] = true
+	}
+}
+
+// IsPublicKey reports whether key is registered as public for all errors
+func IsPublicKey(key string) bool {
+	publicKeys.RLock()
+	defer publicKeys.RUnlock()
+	return publicKeys.keys[key]
+}
+
+// AppendPublicAttributes adds pairs of attribute-values to the SErr, marking their keys public.
+// The keys stay public through later wraps
+func (se *SErr) AppendPublicAttributes(attrs ...any) {
+	attrs = fixupFields(attrs)
+	se.fields = append(se.fields, attrs...)
+
+	// Slicing to capacity forces a copy, as the keys may be shared with errors wrapping this one
+	keys := se.publicKeys[:len(se.publicKeys):len(se.publicKeys)]
+	for i := 0; i < len(attrs); i += 2 {
+		keys = append(keys, fmt.Sprintf("%v", attrs[i]))
+	}
+	se.publicKeys = keys
+}
+
+// IsPublic reports whether the attribute key is public on this error
+func (se SErr) IsPublic(key string) bool {
+	for _, k := range se.publicKeys {
+		if k == key {
+			return true
+		}
+	}
+	return IsPublicKey(key)
+}
+
+// PublicFields returns the public attributes as a map of string keys and values.
+// As with FieldsMap, values of duplicate fields are appended together with ' - '
+func (se SErr) PublicFields() map[string]string {
+	flds := se.FieldsMap()
+	for key := range flds {
+		if !se.IsPublic(key) {
+			delete(flds, key)
+		}
+	}
+	return flds
+}
+
+// PublicFieldsMapOfAny returns the public attributes as a map[string]any
+func (se SErr) PublicFieldsMapOfAny() map[string]any {
+	flds := se.FieldsMapOfAny()
+	for key := range flds {
+		if !se.IsPublic(key) {
+			delete(flds, key)
+		}
+	}
+	return flds
+}
+
+// PublicJSON renders the public attributes as a JSON object
+func (se SErr) PublicJSON() ([]byte, error) {
+	return json.Marshal(se.PublicFieldsMapOfAny())
+}
+
+// PublicFieldsFromErr returns the public attributes of err if it is an SErr
+func PublicFieldsFromErr(err error) map[string]string {
+	if ser, ok := err.(SErr); ok {
+		return ser.PublicFields()
+	}
+	return map[string]string{}
+}
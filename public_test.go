@@ -0,0 +1,59 @@
+package serr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPublicFields(t *testing.T) {
+	se := NewSErr("query failed", "sql", "SELECT * FROM users", "host", "db-1.internal")
+	se.AppendPublicAttributes("order_id", "A-100")
+	se.SetUserMsg("We could not load your order", Severity.Error)
+
+	outer := WrapAsSErr(se, "request_id", "r-1", "order_id", "A-101")
+
+	pub := outer.PublicFields()
+	if len(pub) != 3 {
+		t.Errorf("Expected 3 public fields, got %v", pub)
+	}
+	if pub["order_id"] != "A-101 - A-100" {
+		t.Errorf("Expected order_id to stay public across wraps, got '%s'", pub["order_id"])
+	}
+	if pub[UserMsgKey] != "We could not load your order" {
+		t.Errorf("Expected the user message to be public, got '%s'", pub[UserMsgKey])
+	}
+	for _, key := range []string{"sql", "host", "location", "function", "request_id"} {
+		if _, ok := pub[key]; ok {
+			t.Errorf("Expected '%s' to be internal", key)
+		}
+	}
+
+	// Marking keys public on the outer error doesn't affect the inner one
+	outer.AppendPublicAttributes("tenant", "acme")
+	if se.IsPublic("tenant") {
+		t.Error("Expected public keys of a wrapper not to leak into the wrapped error")
+	}
+
+	RegisterPublicKeys("request_id")
+	defer func() {
+		publicKeys.Lock()
+		delete(publicKeys.keys, "request_id")
+		publicKeys.Unlock()
+	}()
+	if _, ok := outer.PublicFields()["request_id"]; !ok {
+		t.Error("Expected a registered key to be public")
+	}
+
+	js, err := se.PublicJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"order_id":"A-100","userMsgKey":"We could not load your order","userMsgSeverityKey":"error"}`
+	if string(js) != expected {
+		t.Errorf("Expected public JSON %s, got %s", expected, js)
+	}
+
+	if len(PublicFieldsFromErr(errors.New("plain"))) != 0 {
+		t.Error("Expected no public fields on a plain error")
+	}
+}
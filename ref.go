@@ -0,0 +1,75 @@
+package serr
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// RefGenerator generates the reference ID given to an SErr when it is first created.
+// The reference stays the same across wraps, so support staff can find the exact log entry
+// from the reference shown to a user. Set it to nil to disable references
+var RefGenerator func() string = NewRefID
+
+// crockford is the Crockford base32 alphabet, which avoids the ambiguous I, L, O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewRefID returns a ULID-like identifier: 26 Crockford base32 characters encoding
+// a 48 bit millisecond timestamp followed by 80 random bits.
+// IDs sort by creation time, to the millisecond
+func NewRefID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint64(b[:8], ms<<16)
+	_, _ = rand.Read(b[6:])
+
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// Ref returns the reference ID of the SErr
+func (se SErr) Ref() string {
+	return se.ref
+}
+
+// RefFromErr returns the reference ID of the first SErr in err's chain
+func RefFromErr(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if ser, ok := e.(SErr); ok && ser.ref != "" {
+			return ser.ref
+		}
+	}
+	return ""
+}
+
+// UserMsgWithRef returns the user message of err, as UserMsgFromErr does,
+// followed by the error's reference ID, e.g. "Payment failed (ref: 01M57WZ8TPDHZY4HHN72EGT47C)"
+func UserMsgWithRef(err error, alt ...string) (msg string) {
+	msg = UserMsgFromErr(err, alt...)
+	if ref := RefFromErr(err); msg != "" && ref != "" {
+		msg += " (ref: " + ref + ")"
+	}
+	return
+}
+
+// refFor returns the reference of an SErr being created from ser,
+// reusing the reference of any SErr in its chain
+func refFor(ser SErr) string {
+	if ser.ref != "" {
+		return ser.ref
+	}
+	if ref := RefFromErr(ser.err); ref != "" {
+		return ref
+	}
+	if RefGenerator != nil {
+		return RefGenerator()
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withRefGenerator swaps RefGenerator for the duration of a test, returning a func to restore it
+func withRefGenerator(gen func() string) (restore func()) {
+	orig := RefGenerator
+	RefGenerator = gen
+	return func() { RefGenerator = orig }
+}
+
+func TestNewRefID(t *testing.T) {
+	a, b := NewRefID(), NewRefID()
+	if len(a) != 26 || a == b {
+		t.Errorf("Expected distinct 26 character IDs, got %s and %s", a, b)
+	}
+	if strings.Trim(a, crockford) != "" {
+		t.Errorf("Expected only Crockford base32 characters, got %s", a)
+	}
+	if a[:10] > b[:10] {
+		t.Errorf("Expected IDs to sort by time, got %s then %s", a, b)
+	}
+}
+
+func TestRefStableAcrossWraps(t *testing.T) {
+	calls := 0
+	defer withRefGenerator(func() string { calls++; return fmt.Sprintf("REF%d", calls) })()
+
+	err := New("disk full")
+	err = Wrap(err, "op", "save")
+	err = fmt.Errorf("handler: %w", err)
+	err = WrapF(err, "request %d", 7)
+
+	se := err.(SErr)
+	if se.Ref() != "REF1" || calls != 1 {
+		t.Errorf("Expected the reference to be generated once and kept, got %s after %d calls", se.Ref(), calls)
+	}
+	if RefFromErr(err) != "REF1" {
+		t.Errorf("Expected RefFromErr to return REF1, got %s", RefFromErr(err))
+	}
+	if !strings.HasSuffix(se.String(), ", ref[REF1]") {
+		t.Errorf("Expected String() to include the reference, got %s", se.String())
+	}
+
+	se.SetUserMsg("Could not save", Severity.Error)
+	if msg := UserMsgWithRef(se); msg != "Could not save (ref: REF1)" {
+		t.Errorf("Unexpected user message with reference: %s", msg)
+	}
+	if msg := UserMsgWithRef(errors.New("plain"), "Something went wrong"); msg != "Something went wrong" {
+		t.Errorf("Expected no reference on a plain error, got %s", msg)
+	}
+
+	js, jerr := json.Marshal(se)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var decoded struct {
+		Error  string
+		Ref    string
+		Fields map[string]any
+	}
+	if jerr = json.Unmarshal(js, &decoded); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if decoded.Error != "handler: disk full" || decoded.Ref != "REF1" || decoded.Fields["msg"] != "request 7" {
+		t.Errorf("Unexpected JSON %s", js)
+	}
+
+	RefGenerator = nil
+	if ref := New("no ref").(SErr).Ref(); ref != "" {
+		t.Errorf("Expected no reference with a nil generator, got %s", ref)
+	}
+}
@@ -0,0 +1,279 @@
+package serr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// RenderOptions configures Render
+type RenderOptions struct {
+	Color bool
+	// NoSource hides the source code around each location
+	NoSource bool
+	// SourceContext is the number of source lines shown before and after each location, default 2
+	SourceContext int
+}
+
+// maxSourceSize keeps the renderer from reading large generated files
+const maxSourceSize = 1 << 20
+
+// ANSI escape sequences used by the renderer
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// renderHiddenKeys are shown in the header rather than in the attribute tables
+var renderHiddenKeys = []string{CodeKey, SeverityKey, UserMsgKey, UserMsgSeverityKey, UserMsgIDKey, UserMsgParamsKey,
+	HintKey, DocURLKey}
+
+// DetectRenderOptions returns the options suited to w: color when w is a terminal,
+// unless the NO_COLOR environment variable is set or TERM is "dumb"
+func DetectRenderOptions(w io.Writer) RenderOptions {
+	return RenderOptions{Color: isTerminal(w) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Render prints err like a compiler diagnostic: a severity header with the message,
+// then each layer from the origin outwards with its location, source code and attributes.
+// Options are detected from w when not given
+//
+//	error[db_down]: db down: connection refused
+//	  ref: 01M57WZ8TP5DSNYFKBFDCP9452
+//	  --> app/repo.go:42 in app.(*Repo).Get
+//	     |
+//	  41 |     row := r.db.QueryRow(q, id)
+//	  42 >     return serr.Wrap(err, "table", "users")
+//	     |
+//	     = table: users
+func Render(w io.Writer, err error, opts ...RenderOptions) error {
+	o := DetectRenderOptions(w)
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.SourceContext <= 0 {
+		o.SourceContext = 2
+	}
+
+	var buf bytes.Buffer
+	r := renderer{w: &buf, opts: o}
+	r.render(err)
+	_, werr := w.Write(buf.Bytes())
+	return werr
+}
+
+// RenderString renders err as Render does, without color unless opts ask for it
+func RenderString(err error, opts ...RenderOptions) string {
+	var buf bytes.Buffer
+	if len(opts) == 0 {
+		opts = []RenderOptions{{}}
+	}
+	_ = Render(&buf, err, opts...)
+	return buf.String()
+}
+
+// renderLayer is what one New or Wrap contributed to an SErr
+type renderLayer struct {
+	location, function string
+	frame              *runtime.Frame
+	attrs              []Field
+}
+
+type renderer struct {
+	w    *bytes.Buffer
+	opts RenderOptions
+}
+
+func (r renderer) style(s string, codes ...string) string {
+	if !r.opts.Color || len(codes) == 0 {
+		return s
+	}
+	return strings.Join(codes, "") + s + ansiReset
+}
+
+func (r renderer) render(err error) {
+	if err == nil {
+		return
+	}
+
+	sev := SeverityOf(err)
+	header := sev.String()
+	if code := CodeOf(err); code != "" {
+		header += "[" + code + "]"
+	}
+	fmt.Fprintf(r.w, "%s: %s\n", r.style(header, ansiBold, severityColor(sev)), r.style(err.Error(), ansiBold))
+
+	if ref := RefFromErr(err); ref != "" {
+		fmt.Fprintf(r.w, "  %s %s\n", r.style("ref:", ansiDim), ref)
+	}
+
+	var ser SErr
+	if !errors.As(err, &ser) {
+		return
+	}
+	if msg, msgSev := ser.UserMsg(); msg != "" {
+		fmt.Fprintf(r.w, "  %s %s %s\n", r.style("user message:", ansiDim), msg, r.style("("+msgSev+")", ansiDim))
+	}
+
+	for i, layer := range renderLayers(ser) {
+		r.renderLayer(layer, i == 0)
+	}
+
+	for _, hint := range Hints(err) {
+		fmt.Fprintf(r.w, "  %s %s\n", r.style("help:", ansiGreen, ansiBold), hint)
+	}
+	if url := DocURL(err); url != "" {
+		fmt.Fprintf(r.w, "  %s %s\n", r.style("docs:", ansiGreen, ansiBold), url)
+	}
+}
+
+func (r renderer) renderLayer(layer renderLayer, origin bool) {
+	if layer.location != "" || layer.function != "" {
+		arrow := "-->"
+		if !origin {
+			arrow = "wrapped at"
+		}
+		where := layer.location
+		if layer.function != "" {
+			if where != "" {
+				where += " in "
+			}
+			where += layer.function
+		}
+		fmt.Fprintf(r.w, "  %s %s\n", r.style(arrow, ansiBlue, ansiBold), where)
+		if !r.opts.NoSource && layer.frame != nil {
+			r.renderSource(layer.frame.File, layer.frame.Line)
+		}
+	} else if len(layer.attrs) > 0 {
+		fmt.Fprintf(r.w, "  %s\n", r.style("attributes", ansiBlue, ansiBold))
+	}
+
+	width := 0
+	for _, attr := range layer.attrs {
+		width = max(width, len(attr.Key))
+	}
+	for _, attr := range layer.attrs {
+		fmt.Fprintf(r.w, "     %s %s%s %v\n", r.style("=", ansiBlue), r.style(attr.Key+":", ansiCyan),
+			strings.Repeat(" ", width-len(attr.Key)), attr.Value)
+	}
+}
+
+// renderSource prints the lines around line of file, when the file is readable
+func (r renderer) renderSource(file string, line int) {
+	if line <= 0 {
+		return
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.Size() > maxSourceSize {
+		return
+	}
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	lines := strings.Split(string(src), "\n")
+	if line > len(lines) {
+		return
+	}
+	first, last := max(line-r.opts.SourceContext, 1), min(line+r.opts.SourceContext, len(lines))
+	gutter := len(fmt.Sprint(last))
+
+	fmt.Fprintf(r.w, "  %*s %s\n", gutter, "", r.style("|", ansiBlue))
+	for n := first; n <= last; n++ {
+		text := strings.ReplaceAll(lines[n-1], "\t", "    ")
+		if n == line {
+			fmt.Fprintf(r.w, "  %s %s %s\n", r.style(fmt.Sprintf("%*d", gutter, n), ansiBlue), r.style(">", ansiBold, ansiRed), r.style(text, ansiBold))
+		} else {
+			fmt.Fprintf(r.w, "  %s %s %s\n", r.style(fmt.Sprintf("%*d", gutter, n), ansiBlue), r.style("|", ansiBlue), r.style(text, ansiDim))
+		}
+	}
+	fmt.Fprintf(r.w, "  %*s %s\n", gutter, "", r.style("|", ansiBlue))
+}
+
+// renderLayers splits the fields of an SErr into the layers that added them, innermost first.
+// A layer is the attributes of a New or Wrap followed by its location and function
+func renderLayers(ser SErr) (layers []renderLayer) {
+	var cur renderLayer
+	flush := func() {
+		if cur.location != "" || cur.function != "" || len(cur.attrs) > 0 {
+			layers = append(layers, cur)
+		}
+		cur = renderLayer{}
+	}
+
+	for i := 0; i+1 < len(ser.fields); i += 2 {
+		key, val := fmt.Sprintf("%v", ser.fields[i]), ser.fields[i+1]
+		switch key {
+		case "location":
+			if cur.location != "" || cur.function != "" {
+				flush()
+			}
+			cur.location = fmt.Sprintf("%v", val)
+		case "function":
+			if cur.function != "" {
+				flush()
+			}
+			cur.function = fmt.Sprintf("%v", val)
+		default:
+			if cur.location != "" || cur.function != "" {
+				flush()
+			}
+			if !contains(renderHiddenKeys, key) {
+				cur.attrs = append(cur.attrs, Field{Key: key, Value: resolveCaller(val)})
+			}
+			continue
+		}
+
+		if cur.frame == nil {
+			if pc, ok := callerPCOf(val); ok {
+				frame := callerFrame(pc).frame
+				cur.frame = &frame
+			}
+		}
+	}
+	flush()
+	return
+}
+
+// callerPCOf returns the program counter behind a location or function value
+func callerPCOf(val any) (uintptr, bool) {
+	switch v := val.(type) {
+	case callerLocation:
+		return uintptr(v), true
+	case callerFunction:
+		return uintptr(v), true
+	}
+	return 0, false
+}
+
+func severityColor(sev SeverityLevel) string {
+	switch {
+	case sev >= SeverityError:
+		return ansiRed
+	case sev == SeverityWarn:
+		return ansiYellow
+	case sev == SeveritySuccess:
+		return ansiGreen
+	}
+	return ansiCyan
+}
@@ -0,0 +1,56 @@
+package serr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	defer withRefGenerator(func() string { return "01TESTREF" })()
+
+	inner := New("no rows", "table", "users")
+	ser := WrapAsSErr(inner, CodeKey, "user_lookup", "user_id", "42")
+	ser.SetUserMsg("User not found", Severity.Warn)
+
+	out := RenderString(ser)
+	for _, part := range []string{
+		"error[user_lookup]: no rows\n",
+		"  ref: 01TESTREF\n",
+		"  user message: User not found (warn)\n",
+		"  --> serr/render_test.go:",
+		" in rohanthewiz/serr.TestRender\n",
+		`>     inner := New("no rows", "table", "users")`,
+		"     = table: users\n",
+		"  wrapped at serr/render_test.go:",
+		`>     ser := WrapAsSErr(inner, CodeKey, "user_lookup", "user_id", "42")`,
+		"     = user_id: 42\n",
+	} {
+		if !strings.Contains(out, part) {
+			t.Errorf("Expected rendering to contain %q, got\n%s", part, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Error("Expected no color by default")
+	}
+	if strings.Index(out, "-->") > strings.Index(out, "wrapped at") {
+		t.Error("Expected the origin before the wrapping layers")
+	}
+
+	noSource := RenderString(ser, RenderOptions{NoSource: true, Color: true})
+	if strings.Contains(noSource, "inner := New") || !strings.Contains(noSource, ansiRed) {
+		t.Errorf("Expected colored output without source, got\n%s", noSource)
+	}
+}
+
+func TestRenderPlainError(t *testing.T) {
+	if out := RenderString(errPlain("boom")); out != "error: boom\n" {
+		t.Errorf("Unexpected rendering %q", out)
+	}
+}
+
+func TestDetectRenderOptions(t *testing.T) {
+	if DetectRenderOptions(&bytes.Buffer{}).Color {
+		t.Error("Expected no color for a non terminal writer")
+	}
+}
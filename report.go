@@ -0,0 +1,304 @@
+package serr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is an error handed to the reporting pipeline
+type Event struct {
+	Err  error
+	Time time.Time
+}
+
+// Reporter delivers batches of events to a destination such as a file, a log service or a webhook.
+// A Reporter that also has a Close() error method is closed when its Dispatcher closes
+type Reporter interface {
+	Report(ctx context.Context, events []Event) error
+}
+
+// ReporterFunc adapts a function to the Reporter interface
+type ReporterFunc func(ctx context.Context, events []Event) error
+
+func (f ReporterFunc) Report(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// OverflowPolicy decides what happens to events reported while the buffer is full
+type OverflowPolicy int
+
+const (
+	DropNewest OverflowPolicy = iota // discard the event being reported (the default)
+	DropOldest                       // discard the oldest buffered event to make room
+	Block                            // wait for room, applying backpressure to the reporting goroutine
+)
+
+// DispatcherOptions configures a Dispatcher. Zero values select the defaults
+type DispatcherOptions struct {
+	BufferSize    int            // maximum buffered events, default 1024
+	BatchSize     int            // maximum events per delivery, default 100
+	FlushInterval time.Duration  // delivery interval for partial batches, default 1s
+	Overflow      OverflowPolicy // what to do when the buffer is full
+	// OnError is called with errors returned by reporters. Failed batches are not retried
+	OnError func(err error)
+}
+
+// DispatcherStats counts events through a Dispatcher
+type DispatcherStats struct {
+	Reported  uint64 // accepted into the buffer
+	Dropped   uint64 // discarded by the overflow policy or after Close
+	Delivered uint64 // handed to all reporters without error
+	Failed    uint64 // part of a batch that a reporter failed to take
+}
+
+var ErrDispatcherClosed = errors.New("serr: dispatcher is closed")
+
+// Dispatcher buffers reported errors and delivers them in batches to its reporters
+// from a single background goroutine
+type Dispatcher struct {
+	opts      DispatcherOptions
+	reporters []Reporter
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	queue   []Event
+	closed  bool
+
+	wake    chan struct{}      // a full batch is waiting
+	flushes chan chan struct{} // Flush requests, answered once the buffer is delivered
+	done    chan struct{}      // closed when the background goroutine exits
+
+	reported, dropped, delivered, failed atomic.Uint64
+}
+
+// NewDispatcher starts a dispatcher delivering to the reporters
+func NewDispatcher(opts DispatcherOptions, reporters ...Reporter) *Dispatcher {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.BatchSize > opts.BufferSize {
+		opts.BatchSize = opts.BufferSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	d := &Dispatcher{
+		opts:      opts,
+		reporters: reporters,
+		queue:     make([]Event, 0, opts.BufferSize),
+		wake:      make(chan struct{}, 1),
+		flushes:   make(chan chan struct{}),
+		done:      make(chan struct{}),
+	}
+	d.notFull = sync.NewCond(&d.mu)
+	go d.run()
+	return d
+}
+
+// Report enqueues err for delivery. It returns false if the event was dropped.
+// A nil err is ignored
+func (d *Dispatcher) Report(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	for !d.closed && len(d.queue) >= d.opts.BufferSize && d.opts.Overflow == Block {
+		d.notFull.Wait()
+	}
+	switch {
+	case d.closed:
+		d.mu.Unlock()
+		d.dropped.Add(1)
+		return false
+	case len(d.queue) >= d.opts.BufferSize:
+		if d.opts.Overflow != DropOldest {
+			d.mu.Unlock()
+			d.dropped.Add(1)
+			return false
+		}
+		d.queue = append(d.queue[:0], d.queue[1:]...)
+		d.dropped.Add(1)
+	}
+	d.queue = append(d.queue, Event{Err: err, Time: time.Now()})
+	batchReady := len(d.queue) >= d.opts.BatchSize
+	d.mu.Unlock()
+
+	d.reported.Add(1)
+	if batchReady {
+		select {
+		case d.wake <- struct{}{}:
+		default: // a wake up is already pending
+		}
+	}
+	return true
+}
+
+// Flush waits until the events reported so far are delivered, or ctx is done
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case d.flushes <- ack:
+	case <-d.done:
+		return nil // Close delivered everything
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events, delivers the buffered ones and closes the reporters.
+// It returns early with ctx's error if ctx is done first; delivery then continues in the background
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrDispatcherClosed
+	}
+	d.closed = true
+	d.notFull.Broadcast()
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the event counters of the dispatcher
+func (d *Dispatcher) Stats() DispatcherStats {
+	return DispatcherStats{
+		Reported:  d.reported.Load(),
+		Dropped:   d.dropped.Load(),
+		Delivered: d.delivered.Load(),
+		Failed:    d.failed.Load(),
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.deliverAll()
+		case <-d.wake:
+			if d.isClosed() {
+				d.deliverAll()
+				d.closeReporters()
+				return
+			}
+			d.deliverFullBatches()
+		case ack := <-d.flushes:
+			d.deliverAll()
+			close(ack)
+		}
+	}
+}
+
+func (d *Dispatcher) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// take removes up to a batch of events from the buffer.
+// With full set, nothing is taken unless a whole batch is waiting
+func (d *Dispatcher) take(full bool) []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := min(len(d.queue), d.opts.BatchSize)
+	if n == 0 || (full && n < d.opts.BatchSize) {
+		return nil
+	}
+	batch := make([]Event, n)
+	copy(batch, d.queue)
+	d.queue = append(d.queue[:0], d.queue[n:]...)
+	d.notFull.Broadcast()
+	return batch
+}
+
+func (d *Dispatcher) deliverFullBatches() {
+	for batch := d.take(true); batch != nil; batch = d.take(true) {
+		d.deliver(batch)
+	}
+}
+
+func (d *Dispatcher) deliverAll() {
+	for batch := d.take(false); batch != nil; batch = d.take(false) {
+		d.deliver(batch)
+	}
+}
+
+func (d *Dispatcher) deliver(batch []Event) {
+	ok := true
+	for _, r := range d.reporters {
+		if err := r.Report(context.Background(), batch); err != nil {
+			ok = false
+			if d.opts.OnError != nil {
+				d.opts.OnError(err)
+			}
+		}
+	}
+	if ok {
+		d.delivered.Add(uint64(len(batch)))
+	} else {
+		d.failed.Add(uint64(len(batch)))
+	}
+}
+
+func (d *Dispatcher) closeReporters() {
+	for _, r := range d.reporters {
+		if closer, ok := r.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil && d.opts.OnError != nil {
+				d.opts.OnError(err)
+			}
+		}
+	}
+}
+
+var defaultDispatcher atomic.Pointer[Dispatcher]
+
+// SetDispatcher sets the dispatcher used by Report, returning the previous one (possibly nil)
+func SetDispatcher(d *Dispatcher) (previous *Dispatcher) {
+	return defaultDispatcher.Swap(d)
+}
+
+// DefaultDispatcher returns the dispatcher used by Report, or nil if none is set
+func DefaultDispatcher() *Dispatcher {
+	return defaultDispatcher.Load()
+}
+
+// Report hands err to the default dispatcher, so errors can be reported once at the top
+// of a request or job without every team wiring their own logger.
+// It returns false if no dispatcher is set or the event was dropped
+func Report(err error) bool {
+	if d := defaultDispatcher.Load(); d != nil {
+		return d.Report(err)
+	}
+	return false
+}
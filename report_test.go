@@ -0,0 +1,183 @@
+package serr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memReporter records delivered batches
+type memReporter struct {
+	mu      sync.Mutex
+	batches [][]Event
+	closed  bool
+	gate    chan struct{} // when set, Report waits on it
+}
+
+func (m *memReporter) Report(ctx context.Context, events []Event) error {
+	if m.gate != nil {
+		<-m.gate
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, events)
+	return nil
+}
+
+func (m *memReporter) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *memReporter) messages() (msgs []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, batch := range m.batches {
+		for _, ev := range batch {
+			msgs = append(msgs, ev.Err.Error())
+		}
+	}
+	return
+}
+
+func buffered(d *Dispatcher) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
+
+func TestDispatcherBatches(t *testing.T) {
+	rep := &memReporter{}
+	d := NewDispatcher(DispatcherOptions{BatchSize: 2, FlushInterval: time.Hour}, rep)
+
+	for _, msg := range []string{"a", "b", "c"} {
+		if !d.Report(New(msg)) {
+			t.Errorf("Expected '%s' to be accepted", msg)
+		}
+	}
+	if d.Report(nil) {
+		t.Error("Expected a nil error to be ignored")
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rep.batches) != 2 || len(rep.batches[0]) != 2 || len(rep.batches[1]) != 1 {
+		t.Errorf("Expected a full batch then the remainder, got %v", rep.batches)
+	}
+	if stats := d.Stats(); stats.Reported != 3 || stats.Delivered != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !rep.closed {
+		t.Error("Expected Close to close the reporter")
+	}
+	if d.Report(New("late")) {
+		t.Error("Expected events reported after Close to be dropped")
+	}
+	if !errors.Is(d.Close(context.Background()), ErrDispatcherClosed) {
+		t.Error("Expected a second Close to fail")
+	}
+}
+
+func TestDispatcherInterval(t *testing.T) {
+	rep := &memReporter{}
+	d := NewDispatcher(DispatcherOptions{FlushInterval: 10 * time.Millisecond}, rep)
+	defer d.Close(context.Background())
+
+	d.Report(New("partial batch"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rep.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if msgs := rep.messages(); len(msgs) != 1 {
+		t.Errorf("Expected the interval to deliver the partial batch, got %v", msgs)
+	}
+}
+
+func TestDispatcherOverflow(t *testing.T) {
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []string
+	}{
+		{DropNewest, []string{"a", "b"}},
+		{DropOldest, []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		// The gate holds the first batch in delivery so the buffer fills up
+		rep := &memReporter{gate: make(chan struct{})}
+		d := NewDispatcher(DispatcherOptions{BufferSize: 2, BatchSize: 1, FlushInterval: time.Hour, Overflow: tt.policy}, rep)
+
+		d.Report(New("first"))
+		for buffered(d) > 0 { // wait for "first" to be taken
+			time.Sleep(time.Millisecond)
+		}
+		d.Report(New("a"))
+		d.Report(New("b"))
+		d.Report(New("c"))
+		close(rep.gate)
+		_ = d.Close(context.Background())
+
+		msgs := rep.messages()
+		if len(msgs) != 3 || msgs[1] != tt.expected[0] || msgs[2] != tt.expected[1] {
+			t.Errorf("Policy %d: expected first followed by %v, got %v", tt.policy, tt.expected, msgs)
+		}
+		if d.Stats().Dropped != 1 {
+			t.Errorf("Policy %d: expected one dropped event, got %d", tt.policy, d.Stats().Dropped)
+		}
+	}
+}
+
+func TestDispatcherBlock(t *testing.T) {
+	rep := &memReporter{gate: make(chan struct{})}
+	d := NewDispatcher(DispatcherOptions{BufferSize: 1, FlushInterval: time.Hour, Overflow: Block}, rep)
+
+	d.Report(New("first")) // taken, then held by the gate
+	d.Report(New("second"))
+	reported := make(chan bool)
+	go func() { reported <- d.Report(New("third")) }()
+
+	select {
+	case <-reported:
+		t.Fatal("Expected Report to block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(rep.gate)
+	if !<-reported {
+		t.Error("Expected the blocked event to be accepted")
+	}
+	_ = d.Close(context.Background())
+	if msgs := rep.messages(); len(msgs) != 3 {
+		t.Errorf("Expected all events delivered, got %v", msgs)
+	}
+}
+
+func TestReportDefaultDispatcher(t *testing.T) {
+	if Report(New("nowhere")) {
+		t.Error("Expected Report without a dispatcher to drop the event")
+	}
+
+	var got []Event
+	d := NewDispatcher(DispatcherOptions{}, ReporterFunc(func(ctx context.Context, events []Event) error {
+		got = append(got, events...)
+		return nil
+	}))
+	prev := SetDispatcher(d)
+	defer SetDispatcher(prev)
+
+	Report(Wrap(New("disk full"), "op", "write"))
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Err.Error() != "disk full" || got[0].Time.IsZero() {
+		t.Errorf("Unexpected events %+v", got)
+	}
+}
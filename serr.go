@@ -1,6 +1,7 @@
 package serr
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -14,6 +15,10 @@ type SErr struct {
 	// support structured logging of the format key1, val1, key2, val2
 	// Repeated keys are allowed and will be concatenated in log output
 	fields []any
+	// keys marked public on this error, in addition to those registered with RegisterPublicKeys
+	publicKeys []string
+	// reference ID, generated when the SErr is first created and kept across wraps
+	ref string
 }
 
 // New returns a new SErr as an error type
@@ -105,7 +110,7 @@ func (se SErr) FieldsMapOfAny() map[string]any {
 			if origVal, ok := flds[key]; ok { // key is already in the map
 				flds[key] = fmt.Sprintf("%v - %v", val, origVal)
 			} else {
-				flds[key] = val
+				flds[key] = resolveCaller(val)
 			}
 		}
 	}
@@ -124,9 +129,9 @@ func (se SErr) FieldsMapOfSliceOfAny() map[string][]any {
 			key = fmt.Sprintf("%v", val)
 		} else {
 			if origArr, ok := flds[key]; ok { // key is already in the map
-				flds[key] = append(origArr, val)
+				flds[key] = append(origArr, resolveCaller(val))
 			} else {
-				flds[key] = []any{val}
+				flds[key] = []any{resolveCaller(val)}
 			}
 		}
 	}
@@ -176,12 +181,29 @@ func (se SErr) FieldsAsCustomString(attrSep, levelSep string) string {
 
 // String satisfies the Stringer interface, so this is the default method called by fmt
 func (se SErr) String() (out string) {
-	return fmt.Sprintf("%s - Error: %s", se.err, se.FieldsAsCustomString(", ", " -> "))
+	out = fmt.Sprintf("%s - Error: %s", se.err, se.FieldsAsCustomString(", ", " -> "))
+	if se.ref != "" {
+		out += ", ref[" + se.ref + "]"
+	}
+	return
+}
+
+// MarshalJSON renders the SErr as a JSON object of the error message, reference ID, hints, doc URL and attributes
+// Values of duplicate fields are appended together as in FieldsMapOfAny
+func (se SErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error  string         `json:"error"`
+		Ref    string         `json:"ref,omitempty"`
+		Hints  []string       `json:"hints,omitempty"`
+		DocURL string         `json:"docURL,omitempty"`
+		Fields map[string]any `json:"fields,omitempty"`
+	}{se.Error(), se.ref, Hints(se), DocURL(se), se.FieldsMapOfAny()})
 }
 
 // Clone returns a new SErr from an existing one
+// The fields are copied so attributes appended to the clone don't affect the original
 func (se SErr) Clone() SErr {
-	return SErr{se.err, se.fields}
+	return SErr{err: se.err, fields: append([]any(nil), se.fields...), publicKeys: se.publicKeys, ref: se.ref}
 }
 
 // GetError returns the wrapped error
@@ -207,17 +229,34 @@ func (se SErr) Fields() (strFields []string) {
 
 // AppendCallerContext adds Function name and location of the call to SErr.`
 // typically used in new or wrapper functions
+// Only the program counter is captured here. File, line and function name
+// are resolved when the error is first rendered (see callerFrame)
 func (se *SErr) AppendCallerContext(frameLevel int) {
-	se.AppendKeyValPairs([]string{
-		"location", FunctionLoc(frameLevel),
-		"function", FunctionName(frameLevel),
-	}...)
+	pc, ok := callerPC(frameLevel) // frames of functions marked with Helper are skipped
+	if !ok {
+		return
+	}
+
+	switch callerFieldsFor(pc) {
+	case LocationOnly:
+		se.fields = append(se.fields, "location", callerLocation(pc))
+	case FunctionOnly:
+		se.fields = append(se.fields, "function", callerFunction(pc))
+	default:
+		se.fields = append(se.fields, "location", callerLocation(pc), "function", callerFunction(pc))
+	}
 }
 
 // newSErr is the core method for creating a new SErr from an existing SErr
 // This is used in Wrap, New and other methods that add key val pairs and context
 func (ser SErr) newSErr(pairs ...string) (out SErr) {
-	out = SErr{err: ser.err} // add the internal error
+	return ser.newSErrAt(FrameLevels.FrameLevel5, pairs...)
+}
+
+// newSErrAt is newSErr with the frame level of the caller context given explicitly
+// frameLevel is counted from AppendCallerContext's call to FunctionLoc
+func (ser SErr) newSErrAt(frameLevel int, pairs ...string) (out SErr) {
+	out = SErr{err: ser.err, publicKeys: ser.publicKeys, ref: refFor(ser)} // add the internal error
 
 	// Add any existing fields first
 	if len(ser.fields) > 0 {
@@ -228,10 +267,18 @@ func (ser SErr) newSErr(pairs ...string) (out SErr) {
 	out.AppendKeyValPairs(pairs...)
 
 	// Add location info on each wrap
-	out.AppendCallerContext(FrameLevels.FrameLevel4)
+	out.AppendCallerContext(frameLevel)
 	return
 }
 
+// withAttributes returns a copy of ser with attrs appended, keeping its reference and public keys
+// The copy can then be passed through newSErr to add caller context
+func withAttributes(ser SErr, attrs ...any) SErr {
+	out := ser.Clone()
+	out.fields = append(out.fields, attrs...)
+	return out
+}
+
 // NewSerrNoContext builds an SErr from an err without addition of frame context.
 // If err already contains a concrete SErr, it is returned
 func NewSerrNoContext(err error) SErr {
@@ -326,13 +373,7 @@ func (se *SErr) SetUserMsg(msg string, sev string) {
 
 // UserMsg is a convenience method to return the user message field
 // This could be a message displayed to the user of the app
+// If several layers set a user message, DefaultUserMsgPolicy decides which is returned
 func (se SErr) UserMsg() (userMsg, severity string) {
-	mp := se.FieldsMap()
-	if str, ok := mp[UserMsgKey]; ok {
-		userMsg = str
-	}
-	if str, ok := mp[UserMsgSeverityKey]; ok {
-		severity = str
-	}
-	return
+	return se.resolveUserMsg(DefaultUserMsgPolicy)
 }
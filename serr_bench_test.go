@@ -0,0 +1,44 @@
+package serr
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+var benchErr error
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = New("benchmark error", "key", "value")
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	base := errors.New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = Wrap(base, "key", "value")
+	}
+}
+
+// BenchmarkWrapDeep wraps three times, as an error bubbling up a call stack would be
+func BenchmarkWrapDeep(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := Wrap(io.EOF, "layer", "data")
+		err = Wrap(err, "layer", "service")
+		benchErr = Wrap(err, "layer", "handler")
+	}
+}
+
+// BenchmarkWrapAndRender includes rendering the error once, as when it is finally logged
+func BenchmarkWrapAndRender(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := Wrap(io.EOF, "layer", "data")
+		benchErr = Wrap(err, "layer", "service")
+		_ = StringFromErr(benchErr)
+	}
+}
@@ -0,0 +1,150 @@
+package serr
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+const SeverityKey = "severity" // error severity key
+
+// SeverityLevel is an ordered severity, from SeverityDebug to SeverityCritical.
+// It can be attached to an SErr with SetSeverity, and is also used to rank user message severities
+type SeverityLevel int
+
+const (
+	SeverityDebug SeverityLevel = iota
+	SeverityInfo
+	SeveritySuccess
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = []string{"debug", "info", "success", "warn", "error", "critical"}
+
+// String returns the lowercase name of the severity, which matches the Severity "enum" values
+func (s SeverityLevel) String() string {
+	if s < SeverityDebug || s > SeverityCritical {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses a severity name, case-insensitively. "warning" and "fatal" are accepted as aliases
+func ParseSeverity(name string) (SeverityLevel, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "warning":
+		return SeverityWarn, nil
+	case "fatal", "crit":
+		return SeverityCritical, nil
+	}
+	for i, n := range severityNames {
+		if n == name {
+			return SeverityLevel(i), nil
+		}
+	}
+	return SeverityError, New("unknown severity", "severity", name)
+}
+
+// MarshalText encodes the severity as its name
+func (s SeverityLevel) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name
+func (s *SeverityLevel) UnmarshalText(text []byte) (err error) {
+	*s, err = ParseSeverity(string(text))
+	return
+}
+
+// SlogLevel maps the severity onto a log/slog level
+func (s SeverityLevel) SlogLevel() slog.Level {
+	switch {
+	case s <= SeverityDebug:
+		return slog.LevelDebug
+	case s <= SeveritySuccess:
+		return slog.LevelInfo
+	case s == SeverityWarn:
+		return slog.LevelWarn
+	case s == SeverityError:
+		return slog.LevelError
+	}
+	return slog.LevelError + 4
+}
+
+// SyslogSeverity maps the severity onto an RFC 5424 syslog severity,
+// from 0 (emergency) to 7 (debug). Success maps to notice
+func (s SeverityLevel) SyslogSeverity() int {
+	switch {
+	case s <= SeverityDebug:
+		return 7
+	case s == SeverityInfo:
+		return 6
+	case s == SeveritySuccess:
+		return 5
+	case s == SeverityWarn:
+		return 4
+	case s == SeverityError:
+		return 3
+	}
+	return 2
+}
+
+// SetSeverity sets the severity of the error itself (as opposed to that of its user message)
+func (se *SErr) SetSeverity(sev SeverityLevel) {
+	se.AppendAttributes(SeverityKey, sev)
+}
+
+// MaxSeverity returns the highest severity set on any SErr in err's chain
+func MaxSeverity(err error) (max SeverityLevel, ok bool) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		ser, isSErr := e.(SErr)
+		if !isSErr {
+			continue
+		}
+		for _, val := range ser.FieldsMapOfSliceOfAny()[SeverityKey] {
+			if sev, valid := severityFromValue(val); valid && (!ok || sev > max) {
+				max, ok = sev, true
+			}
+		}
+	}
+	return
+}
+
+// SeverityOf returns the highest severity in err's chain.
+// An error without a severity is considered SeverityError
+func SeverityOf(err error) SeverityLevel {
+	if sev, ok := MaxSeverity(err); ok {
+		return sev
+	}
+	return SeverityError
+}
+
+// Escalate raises the severity of err to at least sev.
+// err is returned unchanged if its severity is already as high
+func Escalate(err error, sev SeverityLevel) error {
+	if err == nil {
+		return nil
+	}
+	if max, ok := MaxSeverity(err); ok && max >= sev {
+		return err
+	}
+
+	ser := NewSerrNoContext(err).Clone()
+	ser.SetSeverity(sev)
+	return ser
+}
+
+// severityFromValue converts an attribute value into a severity
+func severityFromValue(val any) (SeverityLevel, bool) {
+	switch v := val.(type) {
+	case SeverityLevel:
+		return v, true
+	case string:
+		sev, err := ParseSeverity(v)
+		return sev, err == nil
+	}
+	return SeverityError, false
+}
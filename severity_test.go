@@ -0,0 +1,86 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := map[string]SeverityLevel{
+		"debug": SeverityDebug, "INFO": SeverityInfo, "success": SeveritySuccess,
+		"warn": SeverityWarn, "Warning": SeverityWarn, "error": SeverityError, "critical": SeverityCritical, "fatal": SeverityCritical,
+	}
+	for name, want := range tests {
+		if got, err := ParseSeverity(name); err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseSeverity("bogus"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+
+	// The string "enum" and the typed severity agree
+	if Severity.Warn != SeverityWarn.String() || Severity.Critical != "critical" {
+		t.Errorf("Severity enum and SeverityLevel names differ: %s, %s", Severity.Warn, Severity.Critical)
+	}
+	if !(SeverityDebug < SeverityInfo && SeverityInfo < SeveritySuccess && SeveritySuccess < SeverityWarn &&
+		SeverityWarn < SeverityError && SeverityError < SeverityCritical) {
+		t.Error("Severities are not ordered")
+	}
+}
+
+func TestSeverityMappings(t *testing.T) {
+	if SeverityWarn.SlogLevel() != slog.LevelWarn || SeverityCritical.SlogLevel() <= slog.LevelError {
+		t.Error("Unexpected slog level mapping")
+	}
+	if SeverityDebug.SyslogSeverity() != 7 || SeverityError.SyslogSeverity() != 3 || SeverityCritical.SyslogSeverity() != 2 {
+		t.Error("Unexpected syslog severity mapping")
+	}
+
+	data, err := json.Marshal(map[string]SeverityLevel{"sev": SeverityWarn})
+	if err != nil || string(data) != `{"sev":"warn"}` {
+		t.Errorf("Unexpected JSON encoding %s, %v", data, err)
+	}
+	var decoded map[string]SeverityLevel
+	if err = json.Unmarshal(data, &decoded); err != nil || decoded["sev"] != SeverityWarn {
+		t.Errorf("Unexpected JSON decoding %v, %v", decoded, err)
+	}
+}
+
+func TestSeverityOnSErr(t *testing.T) {
+	base := errors.New("disk full")
+	if SeverityOf(base) != SeverityError {
+		t.Error("Expected an error without a severity to be SeverityError")
+	}
+	if _, ok := MaxSeverity(base); ok {
+		t.Error("Expected no severity on a plain error")
+	}
+
+	se := WrapAsSErr(base)
+	se.SetSeverity(SeverityWarn)
+	outer := Wrap(se, "severity", "info")
+
+	// Severity is found through a non-SErr wrapper too
+	chained := fmt.Errorf("request failed: %w", outer)
+	if sev := SeverityOf(chained); sev != SeverityWarn {
+		t.Errorf("Expected max severity warn, got %s", sev)
+	}
+
+	escalated := Escalate(chained, SeverityCritical)
+	if sev := SeverityOf(escalated); sev != SeverityCritical {
+		t.Errorf("Expected escalated severity critical, got %s", sev)
+	}
+	if SeverityOf(chained) != SeverityWarn {
+		t.Error("Escalate should not modify the original error")
+	}
+	if lowered := Escalate(escalated, SeverityInfo); SeverityOf(lowered) != SeverityCritical ||
+		len(lowered.(SErr).Fields()) != len(escalated.(SErr).Fields()) {
+		t.Error("Escalate should leave an error with a higher severity unchanged")
+	}
+	if !errors.Is(escalated, base) {
+		t.Error("Expected the escalated error to still match the base error")
+	}
+}
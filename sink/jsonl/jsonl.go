@@ -0,0 +1,359 @@
+// Package jsonl is a serr reporter sink writing each error as a JSON line to a local file,
+// with size or time based rotation, gzip compression of rotated files and retention
+package jsonl
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// SyncPolicy decides when written lines are fsynced to disk
+type SyncPolicy int
+
+const (
+	SyncBatch SyncPolicy = iota // after each batch delivered by the dispatcher (the default)
+	SyncEvent                   // after each line
+	SyncNever                   // leave it to the OS
+)
+
+// rotatedTimeFormat sorts lexically in time order
+const rotatedTimeFormat = "20060102T150405.000"
+
+// Options configures a Sink. Zero values disable the corresponding feature
+type Options struct {
+	// Path of the active file, e.g. "/var/log/app/errors.jsonl".
+	// Rotated files are named after it: "errors-20261018T150405.000.jsonl"
+	Path string
+	// MaxSize rotates the file before it would grow past this many bytes
+	MaxSize int64
+	// RotateEvery rotates the file when the wall clock enters a new period, e.g. 24 * time.Hour for daily files (UTC)
+	RotateEvery time.Duration
+	// Compress gzips rotated files
+	Compress bool
+	// MaxAge removes rotated files older than this
+	MaxAge time.Duration
+	// MaxFiles keeps at most this many rotated files
+	MaxFiles int
+	Sync     SyncPolicy
+	// FileMode of created files, default 0644
+	FileMode os.FileMode
+
+	now func() time.Time // overridden in tests
+}
+
+// Sink is a serr.Reporter writing to a rotating JSON lines file.
+// Rotation, compression and retention run synchronously on the reporting goroutine
+type Sink struct {
+	opts Options
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	period time.Time // start of the RotateEvery period of the active file
+}
+
+// New opens or creates the file at opts.Path for appending
+func New(opts Options) (*Sink, error) {
+	if opts.Path == "" {
+		return nil, serr.New("jsonl sink needs a file path")
+	}
+	if opts.FileMode == 0 {
+		opts.FileMode = 0o644
+	}
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+
+	s := &Sink{opts: opts}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	// A file left by a previous run belongs to the period it was last written in
+	if info, err := s.file.Stat(); err == nil && s.size > 0 {
+		s.period = s.periodOf(info.ModTime())
+	}
+	return s, nil
+}
+
+// Report writes the events, one JSON object per line
+func (s *Sink) Report(ctx context.Context, events []serr.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return serr.New("jsonl sink is closed", "path", s.opts.Path)
+	}
+
+	for _, ev := range events {
+		line, err := EncodeEvent(ev)
+		if err != nil {
+			return err
+		}
+		if err = s.rotateIfNeeded(int64(len(line))); err != nil {
+			return err
+		}
+
+		n, err := s.file.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return serr.Wrap(err, "unable to write error line", "path", s.opts.Path)
+		}
+		if s.opts.Sync == SyncEvent {
+			if err = s.file.Sync(); err != nil {
+				return serr.Wrap(err, "path", s.opts.Path)
+			}
+		}
+	}
+
+	if s.opts.Sync == SyncBatch {
+		if err := s.file.Sync(); err != nil {
+			return serr.Wrap(err, "path", s.opts.Path)
+		}
+	}
+	return nil
+}
+
+// Rotate moves the active file aside, as a rotated file, and starts a new one
+func (s *Sink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotate()
+}
+
+// Close syncs and closes the active file
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.file.Sync(), s.file.Close())
+	s.file = nil
+	if err != nil {
+		return serr.Wrap(err, "path", s.opts.Path)
+	}
+	return nil
+}
+
+// line is the JSON shape of an event
+type line struct {
+	Time   time.Time      `json:"time"`
+	Error  string         `json:"error"`
+	Ref    string         `json:"ref,omitempty"`
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// EncodeEvent renders an event as a JSON line, terminated by a newline.
+// Attribute values that can't be encoded as JSON are written as their %v string
+func EncodeEvent(ev serr.Event) ([]byte, error) {
+	l := line{Time: ev.Time.UTC(), Error: ev.Err.Error(), Ref: serr.RefFromErr(ev.Err)}
+	var ser serr.SErr
+	if errors.As(ev.Err, &ser) {
+		l.Fields = serr.JSONSafeFields(ser.FieldsMapOfAny())
+	}
+
+	byts, err := json.Marshal(l)
+	if err != nil {
+		return nil, serr.Wrap(err, "unable to encode error line")
+	}
+	return append(byts, '\n'), nil
+}
+
+func (s *Sink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.opts.Path), 0o755); err != nil {
+		return serr.Wrap(err, "path", s.opts.Path)
+	}
+	f, err := os.OpenFile(s.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, s.opts.FileMode)
+	if err != nil {
+		return serr.Wrap(err, "unable to open error file", "path", s.opts.Path)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return serr.Wrap(err, "path", s.opts.Path)
+	}
+
+	s.file, s.size, s.period = f, info.Size(), s.periodOf(s.opts.now())
+	return nil
+}
+
+func (s *Sink) periodOf(t time.Time) time.Time {
+	if s.opts.RotateEvery <= 0 {
+		return time.Time{}
+	}
+	return t.UTC().Truncate(s.opts.RotateEvery)
+}
+
+func (s *Sink) rotateIfNeeded(lineLen int64) error {
+	if s.size == 0 {
+		return nil
+	}
+	if s.opts.MaxSize > 0 && s.size+lineLen > s.opts.MaxSize {
+		return s.rotate()
+	}
+	if s.opts.RotateEvery > 0 && !s.periodOf(s.opts.now()).Equal(s.period) {
+		return s.rotate()
+	}
+	return nil
+}
+
+func (s *Sink) rotate() error {
+	if s.file != nil {
+		if err := errors.Join(s.file.Sync(), s.file.Close()); err != nil {
+			return serr.Wrap(err, "path", s.opts.Path)
+		}
+		s.file = nil
+	}
+
+	rotated := s.rotatedName(s.opts.now())
+	if err := os.Rename(s.opts.Path, rotated); err != nil && !os.IsNotExist(err) {
+		return serr.Wrap(err, "unable to rotate error file", "path", s.opts.Path)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if s.opts.Compress {
+		if err := compress(rotated); err != nil {
+			return err
+		}
+	}
+	return s.applyRetention()
+}
+
+// rotatedName returns an unused name for a file rotated at t
+func (s *Sink) rotatedName(t time.Time) string {
+	dir, prefix, ext := s.nameParts()
+	stamp := t.UTC().Format(rotatedTimeFormat)
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, prefix+stamp+ext)
+		if i > 0 {
+			name = filepath.Join(dir, fmt.Sprintf("%s%s_%d%s", prefix, stamp, i, ext))
+		}
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			if _, err = os.Stat(name + ".gz"); os.IsNotExist(err) {
+				return name
+			}
+		}
+	}
+}
+
+// nameParts splits Path into the directory, the rotated file prefix and the extension
+func (s *Sink) nameParts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(s.opts.Path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// compress gzips a rotated file and removes the original
+func compress(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return serr.Wrap(err, "path", name)
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return serr.Wrap(err, "path", tmp)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err = errors.Join(err, zw.Close(), dst.Sync(), dst.Close()); err != nil {
+		return serr.Wrap(err, "unable to compress rotated file", "path", name)
+	}
+	if err = os.Rename(tmp, name+".gz"); err != nil {
+		return serr.Wrap(err, "path", tmp)
+	}
+	if err = os.Remove(name); err != nil {
+		return serr.Wrap(err, "path", name)
+	}
+	return nil
+}
+
+// applyRetention removes rotated files beyond MaxFiles or older than MaxAge
+func (s *Sink) applyRetention() error {
+	if s.opts.MaxFiles <= 0 && s.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	rotated, err := s.RotatedFiles()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := s.opts.now().Add(-s.opts.MaxAge)
+	for i, name := range rotated {
+		expired := s.opts.MaxFiles > 0 && len(rotated)-i > s.opts.MaxFiles
+		if !expired && s.opts.MaxAge > 0 {
+			expired = s.rotatedAt(name).Before(cutoff)
+		}
+		if expired {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return serr.Wrap(err, "unable to remove expired error files")
+	}
+	return nil
+}
+
+// rotatedAt returns when a rotated file was rotated, from its name or else its modification time
+func (s *Sink) rotatedAt(name string) time.Time {
+	_, prefix, _ := s.nameParts()
+	stamp := strings.TrimPrefix(filepath.Base(name), prefix)
+	if len(stamp) >= len(rotatedTimeFormat) {
+		if t, err := time.Parse(rotatedTimeFormat, stamp[:len(rotatedTimeFormat)]); err == nil {
+			return t
+		}
+	}
+	if info, err := os.Stat(name); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// RotatedFiles lists the rotated files of the sink, oldest first
+func (s *Sink) RotatedFiles() ([]string, error) {
+	dir, prefix, ext := s.nameParts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, serr.Wrap(err, "path", dir)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, prefix) && (strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz")) {
+			names = append(names, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,158 @@
+package jsonl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// clock is a settable time source for rotation tests
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func event(msg string, t time.Time) serr.Event {
+	return serr.Event{Err: serr.Wrap(serr.New(msg, "user", "u1"), "op", "sync"), Time: t}
+}
+
+func readLines(t *testing.T, name string) (lines []map[string]any) {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r = bufio.NewScanner(f)
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bufio.NewScanner(zr)
+	}
+	for r.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(r.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", r.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+func TestSinkWritesLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "errors.jsonl")
+	sink, err := New(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	bad := serr.NewSErr("bad attr")
+	bad.AppendAttributes("fn", func() {})
+	unencodable := serr.Event{Err: bad, Time: now}
+	if err = sink.Report(context.Background(), []serr.Event{event("db down", now), unencodable}); err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	fields, _ := lines[0]["fields"].(map[string]any)
+	if lines[0]["error"] != "db down" || lines[0]["time"] != "2026-10-18T12:00:00Z" || fields["op"] != "sync" || fields["user"] != "u1" {
+		t.Errorf("Unexpected line %v", lines[0])
+	}
+	if lines[0]["ref"] == "" || lines[0]["ref"] == nil {
+		t.Errorf("Expected the line to carry the error reference, got %v", lines[0])
+	}
+
+	if err = sink.Report(context.Background(), []serr.Event{event("late", now)}); err == nil {
+		t.Error("Expected Report after Close to fail")
+	}
+}
+
+func TestSinkRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	clk := &clock{t: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	sink, err := New(Options{Path: filepath.Join(dir, "errors.jsonl"), MaxSize: 200, Compress: true, MaxFiles: 2, now: clk.now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 5; i++ {
+		clk.t = clk.t.Add(time.Second)
+		if err = sink.Report(context.Background(), []serr.Event{event("disk full", clk.t)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotated, err := sink.RotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("Expected retention to keep 2 rotated files, got %v", rotated)
+	}
+	for _, name := range rotated {
+		if !strings.HasSuffix(name, ".jsonl.gz") {
+			t.Errorf("Expected rotated files to be compressed, got '%s'", name)
+		}
+		if lines := readLines(t, name); len(lines) != 1 {
+			t.Errorf("Expected one line per rotated file, got %d in '%s'", len(lines), name)
+		}
+	}
+	if !strings.HasSuffix(rotated[1], "errors-20261018T120005.000.jsonl.gz") {
+		t.Errorf("Expected the newest rotated files to be kept, got %v", rotated)
+	}
+}
+
+func TestSinkRotatesByTime(t *testing.T) {
+	dir := t.TempDir()
+	clk := &clock{t: time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)}
+	sink, err := New(Options{Path: filepath.Join(dir, "errors.jsonl"), RotateEvery: 24 * time.Hour, MaxAge: time.Hour, now: clk.now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	report := func(msg string) {
+		if err := sink.Report(context.Background(), []serr.Event{event(msg, clk.t)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report("before midnight")
+	report("same day")
+	clk.t = clk.t.Add(2 * time.Minute)
+	report("next day")
+
+	rotated, _ := sink.RotatedFiles()
+	if len(rotated) != 1 || len(readLines(t, rotated[0])) != 2 {
+		t.Fatalf("Expected the previous day in one rotated file, got %v", rotated)
+	}
+	if lines := readLines(t, filepath.Join(dir, "errors.jsonl")); len(lines) != 1 || lines[0]["error"] != "next day" {
+		t.Errorf("Expected the active file to start the new day, got %v", lines)
+	}
+
+	// Rotated files older than MaxAge are removed on the next rotation
+	clk.t = clk.t.Add(2 * time.Hour)
+	if err = sink.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if rotated, _ = sink.RotatedFiles(); len(rotated) != 1 || !strings.Contains(rotated[0], "20261019T020100") {
+		t.Errorf("Expected the expired file to be removed, got %v", rotated)
+	}
+}
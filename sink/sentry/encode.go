@@ -0,0 +1,216 @@
+// Package sentry encodes serr errors as Sentry events and posts them to a Sentry compatible DSN
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// Event is the subset of the Sentry event payload produced by the Encoder
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Message     *Message          `json:"message,omitempty"`
+	Exception   *Exceptions       `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+}
+
+// Message carries the user message of the error
+type Message struct {
+	Formatted string `json:"formatted"`
+}
+
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace frames are ordered oldest call first, as Sentry expects
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// defaultTagKeys are the attributes indexed as tags, the rest go into extra
+var defaultTagKeys = []string{serr.CodeKey, serr.KindKey, serr.HTTPStatusKey}
+
+// Encoder converts reported errors into Sentry events
+type Encoder struct {
+	Release     string
+	Environment string
+	ServerName  string // defaults to the host name
+	// TagKeys lists attributes to send as searchable tags, in addition to code, kind and httpStatus.
+	// Other attributes are sent as extra data
+	TagKeys []string
+	// Fingerprint groups events into issues. The default is the code of the error, or
+	// Sentry's own grouping when it has none. Use serr.Fingerprint to group by message and wrap trail
+	Fingerprint func(err error) []string
+}
+
+// Encode builds the Sentry event of a reported error
+func (enc Encoder) Encode(ev serr.Event) *Event {
+	out := &Event{
+		EventID:     newEventID(),
+		Timestamp:   ev.Time.UTC(),
+		Platform:    "go",
+		Level:       level(serr.SeverityOf(ev.Err)),
+		Release:     enc.Release,
+		Environment: enc.Environment,
+		ServerName:  enc.ServerName,
+		Tags:        map[string]string{},
+		Extra:       map[string]any{},
+	}
+	if out.ServerName == "" {
+		out.ServerName, _ = os.Hostname()
+	}
+	if out.Timestamp.IsZero() {
+		out.Timestamp = time.Now().UTC()
+	}
+
+	exc := Exception{Type: exceptionType(ev.Err), Value: ev.Err.Error()}
+
+	var ser serr.SErr
+	if errors.As(ev.Err, &ser) {
+		tagKeys := append(append([]string(nil), defaultTagKeys...), enc.TagKeys...)
+		for key, val := range ser.FieldsMapOfAny() {
+			switch {
+			case key == "location" || key == "function" || key == serr.UserMsgKey || key == serr.UserMsgSeverityKey:
+				// carried by the stack trace and message
+			case contains(tagKeys, key):
+				out.Tags[key] = fmt.Sprintf("%v", val)
+			default:
+				out.Extra[key] = val
+			}
+		}
+
+		exc.Stacktrace = stacktrace(ser.Frames())
+		if n := len(exc.Stacktrace.Frames); n > 0 {
+			exc.Module = exc.Stacktrace.Frames[n-1].Module
+		}
+		if msg, _ := ser.UserMsg(); msg != "" {
+			out.Message = &Message{Formatted: msg}
+		}
+	}
+	if ref := serr.RefFromErr(ev.Err); ref != "" {
+		out.Tags["ref"] = ref
+	}
+	out.Exception = &Exceptions{Values: []Exception{exc}}
+
+	if enc.Fingerprint != nil {
+		out.Fingerprint = enc.Fingerprint(ev.Err)
+	} else if code := serr.CodeOf(ev.Err); code != "" {
+		out.Fingerprint = []string{code}
+	}
+	return out
+}
+
+// exceptionType is the error code, or else the Go type of the innermost error
+func exceptionType(err error) string {
+	if code := serr.CodeOf(err); code != "" {
+		return code
+	}
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = inner
+	}
+}
+
+// stacktrace converts the frames of an SErr, innermost first, into a Sentry stack trace
+func stacktrace(frames []runtime.Frame) *Stacktrace {
+	st := &Stacktrace{Frames: make([]Frame, 0, len(frames))}
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		module, function := splitFunction(f.Function)
+		st.Frames = append(st.Frames, Frame{
+			Filename: filepath.Base(f.File),
+			AbsPath:  f.File,
+			Function: function,
+			Module:   module,
+			Lineno:   f.Line,
+			InApp:    inApp(f.File),
+		})
+	}
+	return st
+}
+
+// splitFunction splits "github.com/acme/app/users.(*Store).Get" into the package path and "(*Store).Get"
+func splitFunction(name string) (module, function string) {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot], name[slash+2+dot:]
+	}
+	return "", name
+}
+
+// inApp reports whether a file belongs to the application rather than the standard library or the module cache
+func inApp(file string) bool {
+	file = filepath.ToSlash(file)
+	if strings.Contains(file, "/pkg/mod/") {
+		return false
+	}
+	goroot := filepath.ToSlash(runtime.GOROOT())
+	return goroot == "" || !strings.HasPrefix(file, goroot+"/")
+}
+
+// level maps a serr severity onto a Sentry level
+func level(sev serr.SeverityLevel) string {
+	switch {
+	case sev <= serr.SeverityDebug:
+		return "debug"
+	case sev <= serr.SeveritySuccess:
+		return "info"
+	case sev == serr.SeverityWarn:
+		return "warning"
+	case sev == serr.SeverityError:
+		return "error"
+	}
+	return "fatal"
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
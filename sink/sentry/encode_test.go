@@ -0,0 +1,79 @@
+package sentry
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+var errOrderFailed = serr.Define("order_failed", "order {id} failed", serr.KindConflict)
+
+func TestEncode(t *testing.T) {
+	err := errOrderFailed.Wrap(io.ErrUnexpectedEOF, 7)
+	wrapped := serr.Wrap(err, "tenant", "acme", "region", "eu", serr.UserMsgKey, "Your order could not be placed")
+
+	enc := Encoder{Release: "app@1.2.3", Environment: "prod", ServerName: "edge-1", TagKeys: []string{"tenant"}}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ev := enc.Encode(serr.Event{Err: wrapped, Time: now})
+
+	if len(ev.EventID) != 32 || ev.Platform != "go" || ev.Level != "error" || !ev.Timestamp.Equal(now) {
+		t.Errorf("Unexpected event header %+v", ev)
+	}
+	if ev.Release != "app@1.2.3" || ev.Environment != "prod" || ev.ServerName != "edge-1" {
+		t.Errorf("Expected release, environment and server name to be set, got %+v", ev)
+	}
+	if ev.Tags["code"] != "order_failed" || ev.Tags["kind"] != "conflict" || ev.Tags["tenant"] != "acme" || ev.Tags["ref"] == "" {
+		t.Errorf("Unexpected tags %v", ev.Tags)
+	}
+	if ev.Extra["region"] != "eu" || ev.Extra["id"] != 7 {
+		t.Errorf("Unexpected extra %v", ev.Extra)
+	}
+	if _, ok := ev.Extra["location"]; ok {
+		t.Error("Expected locations to be left to the stack trace")
+	}
+	if ev.Message == nil || ev.Message.Formatted != "Your order could not be placed" {
+		t.Errorf("Expected the user message, got %+v", ev.Message)
+	}
+	if len(ev.Fingerprint) != 1 || ev.Fingerprint[0] != "order_failed" {
+		t.Errorf("Expected the code as fingerprint, got %v", ev.Fingerprint)
+	}
+
+	exc := ev.Exception.Values[0]
+	if exc.Type != "order_failed" || exc.Value != "order 7 failed: unexpected EOF" {
+		t.Errorf("Unexpected exception %+v", exc)
+	}
+	frames := exc.Stacktrace.Frames
+	if len(frames) != 2 {
+		t.Fatalf("Expected a frame per wrap, got %+v", frames)
+	}
+	last := frames[len(frames)-1]
+	if last.Function != "TestEncode" || last.Module != "github.com/rohanthewiz/serr/sink/sentry" ||
+		last.Filename != "encode_test.go" || !last.InApp || last.Lineno == 0 {
+		t.Errorf("Unexpected frame %+v", last)
+	}
+}
+
+func TestEncodePlainError(t *testing.T) {
+	ev := Encoder{ServerName: "edge-1"}.Encode(serr.Event{Err: io.EOF})
+	exc := ev.Exception.Values[0]
+	if exc.Type != "*errors.errorString" || exc.Value != "EOF" || exc.Stacktrace != nil {
+		t.Errorf("Unexpected exception %+v", exc)
+	}
+	if ev.Fingerprint != nil || ev.Timestamp.IsZero() {
+		t.Errorf("Unexpected event %+v", ev)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := map[serr.SeverityLevel]string{
+		serr.SeverityDebug: "debug", serr.SeverityInfo: "info", serr.SeveritySuccess: "info", serr.SeverityWarn: "warning",
+		serr.SeverityError: "error", serr.SeverityCritical: "fatal",
+	}
+	for sev, expected := range tests {
+		if got := level(sev); got != expected {
+			t.Errorf("Expected %s to map to '%s', got '%s'", sev, expected, got)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// DSN is a parsed Sentry DSN, e.g. "https://publickey@o1.ingest.sentry.io/42"
+type DSN struct {
+	PublicKey string
+	ProjectID string
+	envelope  string // envelope endpoint URL
+	raw       string
+}
+
+// ParseDSN parses a Sentry DSN
+func ParseDSN(dsn string) (DSN, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return DSN{}, serr.Wrap(err, "invalid Sentry DSN")
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return DSN{}, serr.New("Sentry DSN needs a scheme, public key and host", "dsn", redactDSN(u))
+	}
+
+	path := strings.Trim(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return DSN{}, serr.New("Sentry DSN has no project ID", "dsn", redactDSN(u))
+	}
+
+	prefix := ""
+	if slash > 0 {
+		prefix = "/" + path[:slash]
+	}
+	return DSN{
+		PublicKey: u.User.Username(),
+		ProjectID: project,
+		envelope:  fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		raw:       dsn,
+	}, nil
+}
+
+// redactDSN drops the key from a DSN for error messages
+func redactDSN(u *url.URL) string {
+	c := *u
+	c.User = nil
+	return c.String()
+}
+
+// Options configures a Sink
+type Options struct {
+	DSN string
+	Encoder
+	// Client sends the events, defaults to a client with a 10s timeout
+	Client *http.Client
+}
+
+// Sink is a serr.Reporter posting each reported error to Sentry as an event envelope
+type Sink struct {
+	dsn    DSN
+	enc    Encoder
+	client *http.Client
+
+	mu            sync.Mutex
+	rateLimitedTo time.Time // events are not sent before this time, per the server's Retry-After
+}
+
+// New creates a Sentry sink for opts.DSN
+func New(opts Options) (*Sink, error) {
+	dsn, err := ParseDSN(opts.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sink{dsn: dsn, enc: opts.Encoder, client: opts.Client}, nil
+}
+
+// Report sends each event. Events reported while the server asks to back off are dropped
+func (s *Sink) Report(ctx context.Context, events []serr.Event) error {
+	var errs []error
+	for _, ev := range events {
+		if err := s.Send(ctx, s.enc.Encode(ev)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send posts a single encoded event
+func (s *Sink) Send(ctx context.Context, ev *Event) error {
+	s.mu.Lock()
+	limited := time.Now().Before(s.rateLimitedTo)
+	s.mu.Unlock()
+	if limited {
+		return serr.New("Sentry rate limit in effect, event dropped", "event_id", ev.EventID)
+	}
+
+	body, err := Envelope(ev, s.dsn)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.dsn.envelope, bytes.NewReader(body))
+	if err != nil {
+		return serr.Wrap(err, "unable to create Sentry request")
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=serr-go/1.0, sentry_key=%s", s.dsn.PublicKey))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return serr.Wrap(err, "unable to send Sentry event", "event_id", ev.EventID)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait := 60 * time.Second
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(secs) * time.Second
+		}
+		s.mu.Lock()
+		s.rateLimitedTo = time.Now().Add(wait)
+		s.mu.Unlock()
+	}
+	if resp.StatusCode >= 300 {
+		return serr.New("Sentry rejected the event", "event_id", ev.EventID, "status", strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
+
+// Envelope serializes an event into a Sentry envelope: a header line, an item header line and the event
+func Envelope(ev *Event, dsn DSN) ([]byte, error) {
+	ev.Extra = serr.JSONSafeFields(ev.Extra)
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, serr.Wrap(err, "unable to encode Sentry event")
+	}
+
+	var buf bytes.Buffer
+	header, _ := json.Marshal(map[string]string{
+		"event_id": ev.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      dsn.raw,
+	})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	for _, part := range [][]byte{header, item, payload} {
+		buf.Write(part)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
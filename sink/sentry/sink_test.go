@@ -0,0 +1,90 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rohanthewiz/serr"
+)
+
+func TestParseDSN(t *testing.T) {
+	dsn, err := ParseDSN("https://abc123@sentry.example.com/prefix/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsn.PublicKey != "abc123" || dsn.ProjectID != "42" || dsn.envelope != "https://sentry.example.com/prefix/api/42/envelope/" {
+		t.Errorf("Unexpected DSN %+v", dsn)
+	}
+
+	for _, bad := range []string{"sentry.example.com/42", "https://sentry.example.com/42", "https://key@sentry.example.com/"} {
+		if _, err := ParseDSN(bad); err == nil {
+			t.Errorf("Expected '%s' to be rejected", bad)
+		}
+	}
+}
+
+func TestSinkPostsEnvelopes(t *testing.T) {
+	var bodies [][]byte
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" {
+			t.Errorf("Unexpected path '%s'", r.URL.Path)
+		}
+		if auth := r.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=pub") {
+			t.Errorf("Expected the public key in the auth header, got '%s'", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "30")
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink, err := New(Options{DSN: strings.Replace(srv.URL, "://", "://pub@", 1) + "/42", Encoder: Encoder{Release: "1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []serr.Event{{Err: serr.New("first")}, {Err: serr.New("second")}}
+	if err = sink.Report(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("Expected an envelope per event, got %d", len(bodies))
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(bodies[0]), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("Expected header, item header and event lines, got %q", bodies[0])
+	}
+	var header, item map[string]any
+	var ev Event
+	for i, target := range []any{&header, &item, &ev} {
+		if err := json.Unmarshal(lines[i], target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if header["event_id"] != ev.EventID || item["type"] != "event" || int(item["length"].(float64)) != len(lines[2]) {
+		t.Errorf("Unexpected envelope headers %v %v", header, item)
+	}
+	if ev.Exception.Values[0].Value != "first" || ev.Release != "1.0" {
+		t.Errorf("Unexpected event %+v", ev)
+	}
+
+	// A 429 stops sending until Retry-After has passed
+	status = http.StatusTooManyRequests
+	if err = sink.Report(context.Background(), events); err == nil {
+		t.Error("Expected a rate limited Report to fail")
+	}
+	if len(bodies) != 3 {
+		t.Errorf("Expected the second event to be dropped without a request, got %d requests", len(bodies))
+	}
+}
@@ -0,0 +1,175 @@
+// Package syslog renders serr errors as RFC 5424 syslog messages, with the error attributes
+// as structured data, and writes them to the local syslog socket or a file
+package syslog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// Facility is the syslog facility, the high bits of the message priority
+type Facility int
+
+const (
+	FacilityUser   Facility = 1 // the default
+	FacilityDaemon Facility = 3
+	FacilityLocal0 Facility = 16
+	FacilityLocal1 Facility = 17
+	FacilityLocal2 Facility = 18
+	FacilityLocal3 Facility = 19
+	FacilityLocal4 Facility = 20
+	FacilityLocal5 Facility = 21
+	FacilityLocal6 Facility = 22
+	FacilityLocal7 Facility = 23
+)
+
+// DefaultSDID is the structured data ID of serr attributes.
+// 32473 is the private enterprise number reserved for documentation; set your own in Formatter.SDID
+const DefaultSDID = "serr@32473"
+
+const nilValue = "-"
+
+// Formatter renders RFC 5424 messages. Zero values are filled in from the running process
+type Formatter struct {
+	Facility Facility
+	Hostname string // default os.Hostname
+	AppName  string // default the executable name
+	ProcID   string // default the process ID
+	SDID     string // default DefaultSDID
+	// BOM marks the message as UTF-8, as RFC 5424 recommends. Some daemons show it as garbage
+	BOM bool
+}
+
+// withDefaults fills in the zero values of the formatter
+func (f Formatter) withDefaults() Formatter {
+	if f.Facility == 0 {
+		f.Facility = FacilityUser
+	}
+	if f.Hostname == "" {
+		f.Hostname, _ = os.Hostname()
+	}
+	if f.AppName == "" {
+		f.AppName = filepath.Base(os.Args[0])
+	}
+	if f.ProcID == "" {
+		f.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if f.SDID == "" {
+		f.SDID = DefaultSDID
+	}
+	return f
+}
+
+// Format renders an event as an RFC 5424 message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID name="value" ...] MSG
+//
+// The severity comes from serr.SeverityOf, the MSGID is the error code,
+// and the structured data carries the reference ID and every attribute in the order they were added
+func (f Formatter) Format(ev serr.Event) []byte {
+	f = f.withDefaults()
+	t := ev.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(strconv.Itoa(int(f.Facility)*8 + serr.SeverityOf(ev.Err).SyslogSeverity()))
+	b.WriteString(">1 ")
+	b.WriteString(t.UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+	for _, field := range []struct {
+		val    string
+		maxLen int
+	}{{f.Hostname, 255}, {f.AppName, 48}, {f.ProcID, 128}, {serr.CodeOf(ev.Err), 32}} {
+		b.WriteByte(' ')
+		b.WriteString(headerField(field.val, field.maxLen))
+	}
+	b.WriteByte(' ')
+	b.WriteString(f.structuredData(ev.Err))
+
+	b.WriteByte(' ')
+	if f.BOM {
+		b.WriteString("\ufeff")
+	}
+	b.WriteString(ev.Err.Error())
+	return []byte(b.String())
+}
+
+// structuredData renders the SD-ELEMENT of the error, or the nil value when it has nothing to carry
+func (f Formatter) structuredData(err error) string {
+	var params []string
+	if ref := serr.RefFromErr(err); ref != "" {
+		params = append(params, "ref", ref)
+	}
+	var ser serr.SErr
+	if errors.As(err, &ser) {
+		params = append(params, ser.Fields()...)
+	}
+	if len(params) == 0 {
+		return nilValue
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	b.WriteString(sdName(f.SDID, true))
+	for i := 0; i+1 < len(params); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(sdName(params[i], false))
+		b.WriteString(`="`)
+		b.WriteString(sdValue(params[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// headerField restricts a header field to printable US-ASCII of at most maxLen characters
+func headerField(s string, maxLen int) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < maxLen; i++ {
+		if c := s[i]; c >= 33 && c <= 126 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return nilValue
+	}
+	return b.String()
+}
+
+// sdName makes s a valid SD-NAME: at most 32 printable US-ASCII characters without '=', ' ', ']' or '"'.
+// An SD-ID may also contain '@' followed by an enterprise number
+func sdName(s string, isID bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < 32; i++ {
+		c := s[i]
+		switch {
+		case c < 33 || c > 126 || c == '=' || c == ']' || c == '"':
+			b.WriteByte('_')
+		case c == '@' && !isID:
+			b.WriteByte('_')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// sdValue escapes '"', '\' and ']' in a PARAM-VALUE
+func sdValue(s string) string {
+	return sdValueEscaper.Replace(s)
+}
+
+var sdValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
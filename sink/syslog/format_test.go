@@ -0,0 +1,61 @@
+package syslog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+var testFormatter = Formatter{Facility: FacilityLocal0, Hostname: "edge-1", AppName: "meter", ProcID: "42"}
+
+func TestFormat(t *testing.T) {
+	def := serr.Define("sensor_offline", "sensor {id} offline")
+	err := serr.Wrap(def.New(`a"b]c`), "note", `back\slash`)
+	ev := serr.Event{Err: err, Time: time.Date(2026, 10, 18, 12, 0, 0, 123456789, time.UTC)}
+
+	msg := string(testFormatter.Format(ev))
+
+	// local0 (16) * 8 + error (3)
+	prefix := "<131>1 2026-10-18T12:00:00.123456Z edge-1 meter 42 sensor_offline [serr@32473 ref=\""
+	if !strings.HasPrefix(msg, prefix) {
+		t.Errorf("Expected message to start with %q, got %q", prefix, msg)
+	}
+	for _, part := range []string{` id="a\"b\]c"`, ` code="sensor_offline"`, ` note="back\\slash"`, ` function="`} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("Expected message to contain %q, got %q", part, msg)
+		}
+	}
+	if !strings.HasSuffix(msg, `] sensor a"b]c offline`) {
+		t.Errorf("Expected the error message last, got %q", msg)
+	}
+}
+
+func TestFormatPlainError(t *testing.T) {
+	f := testFormatter
+	f.BOM = true
+	plain := string(f.Format(serr.Event{Err: errPlain("disk full"), Time: time.Unix(0, 0)}))
+	if plain != "<131>1 1970-01-01T00:00:00.000000Z edge-1 meter 42 - - \ufeffdisk full" {
+		t.Errorf("Unexpected message %q", plain)
+	}
+}
+
+type errPlain string
+
+func (e errPlain) Error() string { return string(e) }
+
+func TestSDName(t *testing.T) {
+	tests := map[string]string{
+		"user id":      "user_id",
+		`a="b"]`:       "a__b__",
+		"user@example": "user_example",
+		"":             "_",
+		"a_very_long_attribute_name_over_32_chars": "a_very_long_attribute_name_over_",
+	}
+	for in, expected := range tests {
+		if got := sdName(in, false); got != expected {
+			t.Errorf("Expected sdName(%q) to be %q, got %q", in, expected, got)
+		}
+	}
+}
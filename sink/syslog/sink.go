@@ -0,0 +1,126 @@
+package syslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// DefaultSocket is the local syslog daemon socket on most Unix systems
+const DefaultSocket = "/dev/log"
+
+// Options configures a Sink
+type Options struct {
+	Formatter
+	// Socket is the Unix datagram socket of the syslog daemon, default DefaultSocket
+	Socket string
+	// Path, when set, appends newline terminated messages to this file instead of using the socket
+	Path string
+}
+
+// Sink is a serr.Reporter writing RFC 5424 messages to the local syslog daemon or a file
+type Sink struct {
+	opts Options
+	fmt  Formatter
+
+	mu     sync.Mutex
+	w      io.WriteCloser
+	closed bool
+}
+
+// New opens the socket or file of the sink
+func New(opts Options) (*Sink, error) {
+	if opts.Socket == "" {
+		opts.Socket = DefaultSocket
+	}
+	s := &Sink{opts: opts, fmt: opts.Formatter.withDefaults()}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Report writes a message per event. A socket write that fails is retried once on a new connection,
+// as the daemon may have restarted
+func (s *Sink) Report(ctx context.Context, events []serr.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return serr.New("syslog sink is closed")
+	}
+
+	var errs []error
+	for _, ev := range events {
+		msg := s.fmt.Format(ev)
+		if s.opts.Path != "" {
+			msg = append(msg, '\n')
+		}
+		if err := s.write(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the socket or file. Later reports fail
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.w == nil {
+		return nil
+	}
+	err := s.w.Close()
+	s.w = nil
+	return err
+}
+
+func (s *Sink) write(msg []byte) error {
+	if s.w == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	_, err := s.w.Write(msg)
+	if err == nil {
+		return nil
+	}
+	if s.opts.Path != "" {
+		return serr.Wrap(err, "unable to write syslog message", "path", s.opts.Path)
+	}
+
+	_ = s.w.Close()
+	s.w = nil
+	if cerr := s.connect(); cerr != nil {
+		return errors.Join(serr.Wrap(err, "unable to write syslog message"), cerr)
+	}
+	if _, err = s.w.Write(msg); err != nil {
+		return serr.Wrap(err, "unable to write syslog message", "socket", s.opts.Socket)
+	}
+	return nil
+}
+
+func (s *Sink) connect() error {
+	if s.opts.Path != "" {
+		f, err := os.OpenFile(s.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return serr.Wrap(err, "unable to open syslog file", "path", s.opts.Path)
+		}
+		s.w = f
+		return nil
+	}
+
+	conn, err := net.Dial("unixgram", s.opts.Socket)
+	if err != nil {
+		return serr.Wrap(err, "unable to connect to syslog socket", "socket", s.opts.Socket)
+	}
+	s.w = conn
+	return nil
+}
@@ -0,0 +1,80 @@
+package syslog
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohanthewiz/serr"
+)
+
+func TestSinkSocket(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, so avoid the long t.TempDir
+	dir, err := os.MkdirTemp("", "serr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "log")
+	daemon, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("Unix datagram sockets unavailable: %v", err)
+	}
+	defer daemon.Close()
+
+	sink, err := New(Options{Formatter: testFormatter, Socket: socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	events := []serr.Event{{Err: serr.New("first", "pump", "p1")}, {Err: serr.New("second")}}
+	if err = sink.Report(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	for _, expected := range []string{"first", "second"} {
+		n, err := daemon.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, "<131>1 ") || !strings.HasSuffix(msg, "] "+expected) {
+			t.Errorf("Expected a datagram per message, got %q", msg)
+		}
+	}
+}
+
+func TestSinkFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	sink, err := New(Options{Formatter: testFormatter, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Report(context.Background(), []serr.Event{{Err: serr.New("a")}, {Err: serr.New("b")}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Report(context.Background(), []serr.Event{{Err: serr.New("c")}}); err == nil {
+		t.Error("Expected reports after Close to fail")
+	}
+
+	byts, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(byts), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "] b") {
+		t.Errorf("Expected a line per message, got %q", byts)
+	}
+}
+
+func TestSinkNoDaemon(t *testing.T) {
+	if _, err := New(Options{Socket: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Expected an error without a listening socket")
+	}
+}
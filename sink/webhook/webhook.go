@@ -0,0 +1,289 @@
+// Package webhook is a serr reporter sink posting errors to an HTTP endpoint,
+// such as a chat or incident tool, with templated payloads, HMAC signing and retries
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+// Payload is the data a template is executed with
+type Payload struct {
+	Time            time.Time
+	Message         string // the error message
+	Fields          map[string]any
+	Severity        string // severity of the error itself
+	UserMsg         string
+	UserMsgSeverity string
+	Code            string
+	Kind            string
+	Ref             string
+	Hints           []string
+	DocURL          string
+	Err             error `json:"-"`
+}
+
+// Options configures a Sink
+type Options struct {
+	URL     string
+	Method  string // default POST
+	Headers map[string]string
+	// ContentType defaults to application/json
+	ContentType string
+	// Template is a text/template over a Payload. It has a json function for safely
+	// embedding values in JSON, e.g. {"text": {{json .Message}}}.
+	// Without a template the Payload itself is posted as JSON
+	Template string
+	// MinSeverity filters out errors of a lower severity, e.g. serr.SeverityCritical for paging only
+	MinSeverity serr.SeverityLevel
+	// Secret, when set, signs each body with HMAC-SHA256 in SignatureHeader as "sha256=<hex>"
+	Secret          []byte
+	SignatureHeader string // default X-Serr-Signature
+	// MaxAttempts per event, default 3. Network errors, 429 and 5xx responses are retried
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubling up to MaxBackoff. Defaults 500ms and 30s
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// DeadLetterPath is a file to which bodies that could not be delivered are appended as JSON lines
+	DeadLetterPath string
+	Client         *http.Client // default client has a 10s timeout
+
+	sleep func(ctx context.Context, d time.Duration) error // overridden in tests
+}
+
+// Sink is a serr.Reporter posting each reported error to a webhook
+type Sink struct {
+	opts Options
+	tmpl *template.Template
+
+	deadLetterMu sync.Mutex
+}
+
+// New creates a webhook sink, parsing its template
+func New(opts Options) (*Sink, error) {
+	if opts.URL == "" {
+		return nil, serr.New("webhook sink needs a URL")
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = "application/json"
+	}
+	if opts.SignatureHeader == "" {
+		opts.SignatureHeader = "X-Serr-Signature"
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 500 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.sleep == nil {
+		opts.sleep = sleep
+	}
+
+	s := &Sink{opts: opts}
+	if opts.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": jsonValue}).Parse(opts.Template)
+		if err != nil {
+			return nil, serr.Wrap(err, "invalid webhook template")
+		}
+		s.tmpl = tmpl
+	}
+	return s, nil
+}
+
+// Report posts each event at or above MinSeverity
+func (s *Sink) Report(ctx context.Context, events []serr.Event) error {
+	var errs []error
+	for _, ev := range events {
+		if serr.SeverityOf(ev.Err) < s.opts.MinSeverity {
+			continue
+		}
+		if err := s.post(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewPayload collects the template data of an event
+func NewPayload(ev serr.Event) Payload {
+	p := Payload{
+		Time:     ev.Time.UTC(),
+		Message:  ev.Err.Error(),
+		Severity: serr.SeverityOf(ev.Err).String(),
+		Code:     serr.CodeOf(ev.Err),
+		Kind:     string(serr.KindOf(ev.Err)),
+		Ref:      serr.RefFromErr(ev.Err),
+		Hints:    serr.Hints(ev.Err),
+		DocURL:   serr.DocURL(ev.Err),
+		Err:      ev.Err,
+	}
+	var ser serr.SErr
+	if errors.As(ev.Err, &ser) {
+		p.Fields = ser.FieldsMapOfAny()
+		p.UserMsg, p.UserMsgSeverity = ser.UserMsg()
+	}
+	return p
+}
+
+// Body renders the request body of an event
+func (s *Sink) Body(ev serr.Event) ([]byte, error) {
+	p := NewPayload(ev)
+	p.Fields = serr.JSONSafeFields(p.Fields)
+	if s.tmpl == nil {
+		byts, err := json.Marshal(p)
+		if err != nil {
+			return nil, serr.Wrap(err, "unable to encode webhook payload")
+		}
+		return byts, nil
+	}
+
+	var buf bytes.Buffer
+	if err := s.tmpl.Execute(&buf, p); err != nil {
+		return nil, serr.Wrap(err, "unable to render webhook template")
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Sink) post(ctx context.Context, ev serr.Event) error {
+	body, err := s.Body(ev)
+	if err != nil {
+		return err
+	}
+
+	wait := s.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		status, err := s.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable(status) || attempt >= s.opts.MaxAttempts || ctx.Err() != nil {
+			return s.deadLetter(body, status, serr.Wrap(err, "attempts", strconv.Itoa(attempt)))
+		}
+
+		// Jitter keeps many reporters from retrying in step
+		if sleepErr := s.opts.sleep(ctx, wait/2+time.Duration(rand.Int63n(int64(wait/2)+1))); sleepErr != nil {
+			return s.deadLetter(body, status, err)
+		}
+		wait = min(wait*2, s.opts.MaxBackoff)
+	}
+}
+
+// send makes one delivery attempt, returning the response status, or 0 on network errors
+func (s *Sink) send(ctx context.Context, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, s.opts.Method, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, serr.Wrap(err, "unable to create webhook request")
+	}
+	req.Header.Set("Content-Type", s.opts.ContentType)
+	for key, val := range s.opts.Headers {
+		req.Header.Set(key, val)
+	}
+	if len(s.opts.Secret) > 0 {
+		req.Header.Set(s.opts.SignatureHeader, Sign(s.opts.Secret, body))
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return 0, serr.Wrap(err, "unable to post webhook", "url", s.opts.URL)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, serr.New("webhook rejected the error", "url", s.opts.URL, "status", strconv.Itoa(resp.StatusCode))
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature of body, as sent in the signature header: "sha256=<hex HMAC>"
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable reports whether a failed attempt may succeed later
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// deadLetter records an undeliverable body and returns err
+func (s *Sink) deadLetter(body []byte, status int, err error) error {
+	if s.opts.DeadLetterPath == "" {
+		return err
+	}
+
+	rec := struct {
+		Time   time.Time       `json:"time"`
+		URL    string          `json:"url"`
+		Status int             `json:"status,omitempty"`
+		Error  string          `json:"error"`
+		Body   json.RawMessage `json:"body,omitempty"`
+		Raw    string          `json:"raw,omitempty"` // the body when it isn't JSON
+	}{Time: time.Now().UTC(), URL: s.opts.URL, Status: status, Error: err.Error()}
+	if json.Valid(body) {
+		rec.Body = body
+	} else {
+		rec.Raw = string(body)
+	}
+	line, _ := json.Marshal(rec)
+
+	s.deadLetterMu.Lock()
+	defer s.deadLetterMu.Unlock()
+
+	f, ferr := os.OpenFile(s.opts.DeadLetterPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if ferr != nil {
+		return errors.Join(err, serr.Wrap(ferr, "unable to open dead letter file", "path", s.opts.DeadLetterPath))
+	}
+	_, werr := f.Write(append(line, '\n'))
+	if werr = errors.Join(werr, f.Close()); werr != nil {
+		return errors.Join(err, serr.Wrap(werr, "unable to write dead letter", "path", s.opts.DeadLetterPath))
+	}
+	return err
+}
+
+// jsonValue is the template json function
+func jsonValue(v any) (string, error) {
+	byts, err := json.Marshal(v)
+	if err != nil {
+		byts, err = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	return string(byts), err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+)
+
+func noSleep(ctx context.Context, d time.Duration) error { return nil }
+
+func criticalErr() error {
+	ser := serr.NewSErr("database unreachable", "db", "orders")
+	ser.SetSeverity(serr.SeverityCritical)
+	ser.SetUserMsg("Orders are unavailable", serr.Severity.Error)
+	return ser
+}
+
+func TestSinkTemplateAndSignature(t *testing.T) {
+	secret := []byte("s3cret")
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get("X-Serr-Signature"); sig != Sign(secret, body) {
+			t.Errorf("Expected a valid signature, got '%s'", sig)
+		}
+		if r.Header.Get("X-Team") != "payments" {
+			t.Error("Expected custom headers to be sent")
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("Expected the template to produce JSON, got %s", body)
+		}
+	}))
+	defer srv.Close()
+
+	sink, err := New(Options{
+		URL:         srv.URL,
+		Headers:     map[string]string{"X-Team": "payments"},
+		Template:    `{"text": {{json .Message}}, "db": {{json (index .Fields "db")}}, "user": {{json .UserMsg}}, "severity": {{json .Severity}}}`,
+		MinSeverity: serr.SeverityCritical,
+		Secret:      secret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []serr.Event{{Err: serr.New("minor glitch")}, {Err: criticalErr()}}
+	if err = sink.Report(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"text": "database unreachable", "db": "orders", "user": "Orders are unavailable", "severity": "critical"}
+	for key, val := range expected {
+		if got[key] != val {
+			t.Errorf("Expected %s to be '%s', got '%s'", key, val, got[key])
+		}
+	}
+
+	if _, err = New(Options{URL: srv.URL, Template: "{{.Nope"}); err == nil {
+		t.Error("Expected an invalid template to be rejected")
+	}
+}
+
+func TestSinkRetriesThenDeadLetters(t *testing.T) {
+	attempts := 0
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	deadLetters := filepath.Join(t.TempDir(), "dead.jsonl")
+	sink, err := New(Options{URL: srv.URL, MaxAttempts: 4, DeadLetterPath: deadLetters, sleep: noSleep})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = sink.Report(context.Background(), []serr.Event{{Err: criticalErr()}}); err == nil {
+		t.Fatal("Expected a persistent failure to be returned")
+	}
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", attempts)
+	}
+
+	// Client errors are not retried
+	attempts, status = 0, http.StatusBadRequest
+	_ = sink.Report(context.Background(), []serr.Event{{Err: serr.New("bad payload")}})
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt on 400, got %d", attempts)
+	}
+
+	byts, err := os.ReadFile(deadLetters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(byts)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(lines))
+	}
+	var rec struct {
+		Status int
+		Body   Payload
+	}
+	if err = json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != http.StatusServiceUnavailable || rec.Body.Message != "database unreachable" || rec.Body.Fields["db"] != "orders" {
+		t.Errorf("Unexpected dead letter %s", lines[0])
+	}
+}
+
+func TestSinkRecoversOnRetry(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	sink, _ := New(Options{URL: srv.URL, sleep: noSleep})
+	if err := sink.Report(context.Background(), []serr.Event{{Err: serr.New("flaky")}}); err != nil {
+		t.Errorf("Expected the retry to succeed, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
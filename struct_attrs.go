@@ -0,0 +1,123 @@
+package serr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of attributes tagged with the redact option
+const RedactedValue = "[REDACTED]"
+
+// maxStructDepth bounds recursion into nested structs, guarding against pointer cycles
+const maxStructDepth = 8
+
+// AttrsFrom returns attribute key, value pairs for the fields of struct v tagged `serr:"name"`.
+// Untagged fields are ignored. Tag options follow the name:
+//
+//   - omitempty: skip the field if it has its zero value
+//   - redact: replace the value with "[REDACTED]"
+//
+// A tagged struct field (or pointer to struct) is expanded into its own tagged fields,
+// prefixed with its name, e.g. "req.id". Embedded structs are expanded without a prefix.
+// Structs implementing fmt.Stringer or error, like time.Time, are kept as single values.
+// Example:
+//
+//	type Job struct {
+//		ID     string `serr:"job_id"`
+//		Token  string `serr:"token,redact"`
+//		Retry  int    `serr:"retry,omitempty"`
+//		Owner  User   `serr:"owner"` // => owner.id, owner.email ...
+//	}
+func AttrsFrom(v any) []any {
+	return structAttrs(reflect.ValueOf(v), "", 0)
+}
+
+// WrapStruct wraps an existing error with the tagged fields of struct v as attributes (see AttrsFrom)
+func WrapStruct(err error, v any) error {
+	if err == nil {
+		fmt.Println("SErr: Not wrapping a nil error", "callerLocation:", FunctionLoc(FrameLevels.FrameLevel2),
+			"callerName:", FunctionName(FrameLevels.FrameLevel2))
+		return nil
+	}
+
+	se := withAttributes(NewSerrNoContext(err), AttrsFrom(v)...)
+	return se.newSErr()
+}
+
+func structAttrs(val reflect.Value, prefix string, depth int) (attrs []any) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct || depth > maxStructDepth {
+		return nil
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field, fv := typ.Field(i), val.Field(i)
+
+		tag, tagged := field.Tag.Lookup("serr")
+		if !tagged {
+			// Exported fields of embedded structs are promoted, even if the embedded type is unexported
+			if field.Anonymous {
+				attrs = append(attrs, structAttrs(fv, prefix, depth+1)...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		key := prefix + name
+
+		if hasTagOption(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		if hasTagOption(opts, "redact") {
+			attrs = append(attrs, key, RedactedValue)
+			continue
+		}
+		if isNestedStruct(fv) {
+			attrs = append(attrs, structAttrs(fv, key+".", depth+1)...)
+			continue
+		}
+		attrs = append(attrs, key, fv.Interface())
+	}
+	return
+}
+
+// isNestedStruct reports whether a field value should be expanded into its own fields
+func isNestedStruct(val reflect.Value) bool {
+	typ := val.Type()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	stringer := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	ptr := reflect.PointerTo(typ)
+	return !(typ.Implements(stringer) || ptr.Implements(stringer) || typ.Implements(errType) || ptr.Implements(errType))
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package serr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAudit struct {
+	CreatedBy string `serr:"created_by"`
+}
+
+type testUser struct {
+	ID    int    `serr:"id"`
+	Email string `serr:"email,redact"`
+	Notes string
+}
+
+type testJob struct {
+	testAudit
+	ID       string     `serr:"job_id"`
+	Retry    int        `serr:"retry,omitempty"`
+	Owner    *testUser  `serr:"owner"`
+	Started  time.Time  `serr:"started"`
+	Manager  *testUser  `serr:"manager,omitempty"`
+	Internal string     `serr:"-"`
+	Queue    string     `serr:",omitempty"`
+	private  string     `serr:"private"`
+	Parent   *testJob   `serr:"parent,omitempty"`
+	Tags     []string   `serr:"tags"`
+	Deadline *time.Time `serr:"deadline,omitempty"`
+}
+
+func TestAttrsFrom(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	job := testJob{
+		testAudit: testAudit{CreatedBy: "scheduler"},
+		ID:        "job-1",
+		Owner:     &testUser{ID: 7, Email: "a@example.com", Notes: "untagged"},
+		Started:   started,
+		Internal:  "skip me",
+		Queue:     "emails",
+		Tags:      []string{"a", "b"},
+	}
+
+	want := []any{
+		"created_by", "scheduler",
+		"job_id", "job-1",
+		"owner.id", 7,
+		"owner.email", RedactedValue,
+		"started", started,
+		"Queue", "emails",
+		"tags", []string{"a", "b"},
+	}
+	if got := AttrsFrom(&job); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttrsFrom() =\n%v\nwant\n%v", got, want)
+	}
+
+	if AttrsFrom("not a struct") != nil || AttrsFrom((*testJob)(nil)) != nil {
+		t.Error("Expected no attributes from a non-struct or nil pointer")
+	}
+}
+
+func TestWrapStruct(t *testing.T) {
+	if WrapStruct(nil, testUser{}) != nil {
+		t.Error("WrapStruct(nil, ...) should return nil")
+	}
+
+	base := New("send failed")
+	err := WrapStruct(base, testUser{ID: 3, Email: "x@example.com"})
+
+	se, ok := err.(SErr)
+	if !ok {
+		t.Fatal("WrapStruct should return an SErr")
+	}
+	mp := se.FieldsMap()
+	if mp["id"] != "3" || mp["email"] != RedactedValue {
+		t.Errorf("Unexpected attributes %v", mp)
+	}
+	if mp["function"] != "rohanthewiz/serr.TestWrapStruct - rohanthewiz/serr.TestWrapStruct" {
+		t.Errorf("Expected caller context for both layers, got '%s'", mp["function"])
+	}
+	if se.Ref() != base.(SErr).Ref() {
+		t.Error("Expected the reference ID to be kept")
+	}
+	if !errors.Is(err, base.(SErr).GetError()) {
+		t.Error("Expected the wrapped error to match")
+	}
+}
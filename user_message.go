@@ -1,18 +1,34 @@
 package serr
 
+import (
+	"fmt"
+	"strings"
+)
+
 const UserMsgKey = "userMsgKey"                 // user message key
 const UserMsgSeverityKey = "userMsgSeverityKey" // user message severity key
 
+// UserMsgOptions is not used by the package.
+//
+// Deprecated: pass a SeverityLevel's String() to SetUserMsg instead.
 type UserMsgOptions struct {
 	Severity string
 }
 
 // poor man's enum
+// See SeverityLevel for the typed and ordered equivalent
 type severity struct {
-	Success, Error, Warn, Info string
+	Success, Error, Warn, Info, Debug, Critical string
 }
 
-var Severity = severity{"success", "error", "warn", "info"}
+var Severity = severity{
+	Success:  SeveritySuccess.String(),
+	Error:    SeverityError.String(),
+	Warn:     SeverityWarn.String(),
+	Info:     SeverityInfo.String(),
+	Debug:    SeverityDebug.String(),
+	Critical: SeverityCritical.String(),
+}
 
 // UserMsg is a convenience function for getting the user message,
 // and severity fields from a standard error
@@ -26,3 +42,144 @@ func UserMsg(err error) (msg, severity string) {
 	}
 	return
 }
+
+// UserMsgPolicy decides which user message is shown when several layers set one
+type UserMsgPolicy int
+
+const (
+	// UserMsgOutermostWins picks the message set closest to the user, i.e. the last one set
+	UserMsgOutermostWins UserMsgPolicy = iota
+	// UserMsgInnermostWins picks the message set closest to where the error occurred
+	UserMsgInnermostWins
+	// UserMsgHighestSeverityWins picks the most severe message, the outermost one on a tie
+	UserMsgHighestSeverityWins
+	// UserMsgCollectAll keeps every message, outermost first
+	UserMsgCollectAll
+)
+
+// DefaultUserMsgPolicy is the policy used by UserMsg, UserMsgFromErr and SErr.UserMsg
+var DefaultUserMsgPolicy = UserMsgOutermostWins
+
+// UserMessage is a user message along with the layer of the error that set it
+type UserMessage struct {
+	Msg      string
+	Severity string
+	// ID and Params are set for a localized message (see SetUserMsgID)
+	ID     string
+	Params UserMsgParams
+	// Layer is the index of the wrap that set the message, 0 being the innermost.
+	// A message set with SetUserMsg is attributed to the wrap (or New) preceding it
+	Layer    int
+	Location string
+	Function string
+}
+
+// UserMessages returns every user message set on the SErr, innermost first
+func (se SErr) UserMessages() (msgs []UserMessage) {
+	type layer struct{ location, function string }
+	var layers []layer
+	var layerIdxs []int // layer index of each message
+
+	for i := 0; i+1 < len(se.fields); i += 2 {
+		key, val := se.fields[i], se.fields[i+1]
+
+		switch key {
+		case "location":
+			layers = append(layers, layer{location: fmt.Sprintf("%v", val)})
+		case "function":
+			// A wrap records its location before its function, unless locations are disabled
+			if len(layers) == 0 || layers[len(layers)-1].function != "" {
+				layers = append(layers, layer{})
+			}
+			layers[len(layers)-1].function = fmt.Sprintf("%v", val)
+		case UserMsgKey, UserMsgIDKey:
+			// A literal message and a message id set at the same layer form one message,
+			// the literal being the fallback for the localized message
+			last := len(msgs) - 1
+			merge := last >= 0 && layerIdxs[last] == len(layers)-1 &&
+				((key == UserMsgKey && msgs[last].Msg == "") || (key == UserMsgIDKey && msgs[last].ID == ""))
+			if !merge {
+				msgs = append(msgs, UserMessage{})
+				layerIdxs = append(layerIdxs, len(layers)-1)
+			}
+			if key == UserMsgKey {
+				msgs[len(msgs)-1].Msg = fmt.Sprintf("%v", val)
+			} else {
+				msgs[len(msgs)-1].ID = fmt.Sprintf("%v", val)
+			}
+		case UserMsgSeverityKey:
+			if len(msgs) > 0 {
+				msgs[len(msgs)-1].Severity = fmt.Sprintf("%v", val)
+			}
+		case UserMsgParamsKey:
+			if len(msgs) > 0 {
+				msgs[len(msgs)-1].Params, _ = val.(UserMsgParams)
+			}
+		}
+	}
+
+	for i, idx := range layerIdxs {
+		if idx < 0 { // set before the first wrap
+			idx = 0
+		}
+		msgs[i].Layer = idx
+		if idx < len(layers) {
+			msgs[i].Location, msgs[i].Function = layers[idx].location, layers[idx].function
+		}
+	}
+	return
+}
+
+// ResolveUserMsgs applies a policy to the user messages of the SErr.
+// A single message is returned, except for UserMsgCollectAll
+func (se SErr) ResolveUserMsgs(policy UserMsgPolicy) []UserMessage {
+	msgs := se.UserMessages()
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case UserMsgInnermostWins:
+		return msgs[:1]
+	case UserMsgHighestSeverityWins:
+		best := len(msgs) - 1
+		for i := len(msgs) - 2; i >= 0; i-- {
+			if severityRank(msgs[i].Severity) > severityRank(msgs[best].Severity) {
+				best = i
+			}
+		}
+		return msgs[best : best+1]
+	case UserMsgCollectAll:
+		all := make([]UserMessage, 0, len(msgs))
+		for i := len(msgs) - 1; i >= 0; i-- {
+			all = append(all, msgs[i])
+		}
+		return all
+	}
+	return msgs[len(msgs)-1:]
+}
+
+// resolveUserMsg flattens the result of a policy into a message and severity.
+// Collected messages are joined by newlines, with the highest of their severities
+func (se SErr) resolveUserMsg(policy UserMsgPolicy) (msg, severity string) {
+	resolved := se.ResolveUserMsgs(policy)
+
+	var msgs []string
+	for _, um := range resolved {
+		msgs = append(msgs, um.Msg)
+		if severity == "" || severityRank(um.Severity) > severityRank(severity) {
+			severity = um.Severity
+		}
+	}
+	return strings.Join(msgs, "\n"), severity
+}
+
+// severityRank orders user message severities from least to most severe
+// Unknown severities rank lowest
+func severityRank(sev string) int {
+	level, err := ParseSeverity(sev)
+	if err != nil {
+		return -1
+	}
+	return int(level)
+}
@@ -0,0 +1,430 @@
+package serr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const UserMsgIDKey = "userMsgIDKey"         // localized user message catalog key
+const UserMsgParamsKey = "userMsgParamsKey" // localized user message parameters key
+
+// PluralCountParam is the message parameter that selects the plural form of a localized message
+const PluralCountParam = "count"
+
+// UserMsgParams holds the name, value pairs of a localized user message
+type UserMsgParams []any
+
+func (p UserMsgParams) String() string {
+	var arr []string
+	for i := 0; i+1 < len(p); i += 2 {
+		arr = append(arr, fmt.Sprintf("%v=%v", p[i], p[i+1]))
+	}
+	return strings.Join(arr, " ")
+}
+
+// SetUserMsgID sets a user message by its key in the locale catalogs (see Locales),
+// with parameters given as name, value pairs. The message is resolved per language
+// with UserMsgLocalized. A plural form is chosen by the "count" parameter
+// Example:
+//
+//	se.SetUserMsgID("cart.items", serr.Severity.Info, "count", 3)
+func (se *SErr) SetUserMsgID(id string, sev string, params ...any) {
+	se.AppendAttributes(UserMsgIDKey, id, UserMsgSeverityKey, sev, UserMsgParamsKey, UserMsgParams(params))
+}
+
+// UserMsgID returns the localized user message key and its parameters, if set
+func (se SErr) UserMsgID() (id string, params UserMsgParams) {
+	for i := len(se.fields) - 2; i >= 0; i -= 2 {
+		switch se.fields[i] {
+		case UserMsgIDKey:
+			if id == "" {
+				id = fmt.Sprintf("%v", se.fields[i+1])
+			}
+		case UserMsgParamsKey:
+			if params == nil {
+				params, _ = se.fields[i+1].(UserMsgParams)
+			}
+		}
+	}
+	return
+}
+
+// PluralRule returns the CLDR plural category (zero, one, two, few, many or other) of a count
+type PluralRule func(n float64) string
+
+// Locales is a set of user message catalogs, one per language
+type Locales struct {
+	defaultLang string
+	mu          sync.RWMutex
+	messages    map[string]map[string]localizedMsg // lang => msg id => message
+	fallbacks   map[string][]string
+	plurals     map[string]PluralRule
+}
+
+// localizedMsg is a message format with optional plural forms
+type localizedMsg struct {
+	forms map[string]string // plural category => format; "other" is also the singular form
+}
+
+// DefaultLocales are the catalogs used by UserMsgLocalized
+var DefaultLocales = NewLocales("en")
+
+// NewLocales returns an empty set of catalogs, with defaultLang as the final fallback language
+func NewLocales(defaultLang string) *Locales {
+	return &Locales{
+		defaultLang: normalizeLang(defaultLang),
+		messages:    map[string]map[string]localizedMsg{},
+		fallbacks:   map[string][]string{},
+		plurals:     map[string]PluralRule{},
+	}
+}
+
+// Add adds messages for a language. Formats use the named placeholder syntax of NewN
+func (l *Locales) Add(lang string, messages map[string]string) {
+	msgs := map[string]localizedMsg{}
+	for id, format := range messages {
+		msgs[id] = localizedMsg{forms: map[string]string{"other": format}}
+	}
+	l.addMessages(lang, msgs)
+}
+
+// LoadFS loads catalogs from files named <lang>.json in dir of fsys, e.g. an embed.FS.
+// A message is either a string or an object of plural forms:
+//
+//	{
+//	  "payment.declined": "Your payment of {amount} was declined",
+//	  "cart.items": {"one": "You have {count} item", "other": "You have {count} items"}
+//	}
+func (l *Locales) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return Wrap(err, "dir", dir)
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return Wrap(err, "file", file)
+		}
+
+		raw := map[string]json.RawMessage{}
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return Wrap(err, "unable to decode locale catalog", "file", file)
+		}
+
+		msgs := map[string]localizedMsg{}
+		for id, val := range raw {
+			var format string
+			if err = json.Unmarshal(val, &format); err == nil {
+				msgs[id] = localizedMsg{forms: map[string]string{"other": format}}
+				continue
+			}
+
+			forms := map[string]string{}
+			if err = json.Unmarshal(val, &forms); err != nil {
+				return Wrap(err, "locale message must be a string or an object of plural forms", "file", file, "id", id)
+			}
+			msgs[id] = localizedMsg{forms: forms}
+		}
+
+		l.addMessages(strings.TrimSuffix(path.Base(file), ".json"), msgs)
+	}
+	return nil
+}
+
+// LoadDir loads catalogs from files named <lang>.json in a directory
+func (l *Locales) LoadDir(dir string) error {
+	return l.LoadFS(os.DirFS(dir), ".")
+}
+
+func (l *Locales) addMessages(lang string, msgs map[string]localizedMsg) {
+	lang = normalizeLang(lang)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.messages[lang] == nil {
+		l.messages[lang] = map[string]localizedMsg{}
+	}
+	for id, msg := range msgs {
+		l.messages[lang][id] = msg
+	}
+}
+
+// SetFallback sets the languages tried, in order, when a message is missing for lang.
+// By default a regional language falls back to its base language, e.g. pt-BR to pt,
+// and every language falls back to the default language
+func (l *Locales) SetFallback(lang string, fallbacks ...string) {
+	for i := range fallbacks {
+		fallbacks[i] = normalizeLang(fallbacks[i])
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fallbacks[normalizeLang(lang)] = fallbacks
+}
+
+// SetPluralRule sets the plural rule of a language, overriding the built-in rule
+func (l *Locales) SetPluralRule(lang string, rule PluralRule) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.plurals[normalizeLang(lang)] = rule
+}
+
+// Languages returns the languages with a catalog
+func (l *Locales) Languages() (langs []string) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for lang := range l.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return
+}
+
+// Translate renders the message id for lang, following the fallback chain.
+// Params are name, value pairs filling the message placeholders
+func (l *Locales) Translate(lang, id string, params ...any) (msg string, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, lng := range l.fallbackChain(lang) {
+		locMsg, found := l.messages[lng][id]
+		if !found {
+			continue
+		}
+
+		format, found := locMsg.forms["other"]
+		if count, isNum := pluralCount(params); isNum {
+			if form, hasForm := locMsg.forms[l.pluralRule(lng)(count)]; hasForm {
+				format, found = form, true
+			}
+		}
+		if found {
+			return expandNamed(format, params), true
+		}
+	}
+	return "", false
+}
+
+// UserMsg returns the user message of err localized for lang, and its severity.
+// The message is chosen by DefaultUserMsgPolicy. If it is not localized, or its id is missing
+// from every catalog in the fallback chain, the literal user message set with SetUserMsg is returned
+func (l *Locales) UserMsg(err error, lang string) (msg, severity string) {
+	var ser SErr
+	if !errors.As(err, &ser) {
+		return
+	}
+
+	resolved := ser.ResolveUserMsgs(DefaultUserMsgPolicy)
+
+	var msgs []string
+	for _, um := range resolved {
+		if um.ID != "" {
+			if localized, ok := l.Translate(lang, um.ID, um.Params...); ok {
+				um.Msg = localized
+			}
+		}
+		msgs = append(msgs, um.Msg)
+		if severity == "" || severityRank(um.Severity) > severityRank(severity) {
+			severity = um.Severity
+		}
+	}
+	return strings.Join(msgs, "\n"), severity
+}
+
+// UserMsgForRequest localizes the user message of err for the languages accepted by the request
+func (l *Locales) UserMsgForRequest(err error, r *http.Request) (msg, severity string) {
+	return l.UserMsg(err, NegotiateLanguage(r.Header.Get("Accept-Language"), l.Languages()...))
+}
+
+// fallbackChain lists the languages to try for lang, in order
+func (l *Locales) fallbackChain(lang string) (chain []string) {
+	add := func(lng string) {
+		for _, existing := range chain {
+			if existing == lng {
+				return
+			}
+		}
+		chain = append(chain, lng)
+	}
+
+	lang = normalizeLang(lang)
+	add(lang)
+	for _, lng := range l.fallbacks[lang] {
+		add(lng)
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		add(base)
+		for _, lng := range l.fallbacks[base] {
+			add(lng)
+		}
+	}
+	add(l.defaultLang)
+	return
+}
+
+func (l *Locales) pluralRule(lang string) PluralRule {
+	if rule, ok := l.plurals[lang]; ok {
+		return rule
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	if rule, ok := l.plurals[base]; ok {
+		return rule
+	}
+	if rule, ok := builtinPluralRules[base]; ok {
+		return rule
+	}
+	return pluralOneOther
+}
+
+// UserMsgLocalized returns the user message of err localized for lang using DefaultLocales
+func UserMsgLocalized(err error, lang string) (msg, severity string) {
+	return DefaultLocales.UserMsg(err, lang)
+}
+
+// NegotiateLanguage picks the best of the supported languages for an Accept-Language header.
+// A regional preference such as fr-CA matches a supported base language fr, and vice versa.
+// The first supported language is returned if nothing matches
+func NegotiateLanguage(acceptLanguage string, supported ...string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	type pref struct {
+		lang string
+		q    float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if val, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err == nil {
+				q = val
+			}
+		}
+		if q > 0 {
+			prefs = append(prefs, pref{normalizeLang(lang), q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	for _, p := range prefs {
+		if p.lang == "*" {
+			return supported[0]
+		}
+		for _, s := range supported {
+			if normalizeLang(s) == p.lang {
+				return s
+			}
+		}
+		base, _, _ := strings.Cut(p.lang, "-")
+		for _, s := range supported {
+			sBase, _, _ := strings.Cut(normalizeLang(s), "-")
+			if sBase == base {
+				return s
+			}
+		}
+	}
+	return supported[0]
+}
+
+func normalizeLang(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// pluralCount returns the "count" parameter as a number
+func pluralCount(params []any) (float64, bool) {
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i] != PluralCountParam {
+			continue
+		}
+		switch n := params[i+1].(type) {
+		case int:
+			return float64(n), true
+		case int64:
+			return float64(n), true
+		case int32:
+			return float64(n), true
+		case uint:
+			return float64(n), true
+		case uint64:
+			return float64(n), true
+		case float64:
+			return n, true
+		case float32:
+			return float64(n), true
+		}
+	}
+	return 0, false
+}
+
+// Built-in plural rules, simplified from the CLDR rules for integer counts
+
+func pluralOneOther(n float64) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func pluralZeroOneOther(n float64) string { // e.g. French: 0 and 1 are singular
+	if n == 0 || n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func pluralOther(float64) string { // e.g. Japanese: no plural forms
+	return "other"
+}
+
+func pluralSlavic(n float64) string { // e.g. Russian, Ukrainian
+	i := int64(n)
+	if float64(i) != n {
+		return "other"
+	}
+	switch {
+	case i%10 == 1 && i%100 != 11:
+		return "one"
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+func pluralPolish(n float64) string {
+	i := int64(n)
+	if float64(i) != n {
+		return "other"
+	}
+	switch {
+	case i == 1:
+		return "one"
+	case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+var builtinPluralRules = map[string]PluralRule{
+	"en": pluralOneOther, "de": pluralOneOther, "nl": pluralOneOther, "sv": pluralOneOther,
+	"da": pluralOneOther, "no": pluralOneOther, "it": pluralOneOther, "es": pluralOneOther,
+	"pt": pluralZeroOneOther, "fr": pluralZeroOneOther,
+	"ru": pluralSlavic, "uk": pluralSlavic, "pl": pluralPolish,
+	"ja": pluralOther, "zh": pluralOther, "ko": pluralOther, "vi": pluralOther, "th": pluralOther,
+}
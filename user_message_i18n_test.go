@@ -0,0 +1,123 @@
+package serr
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func testLocales(t *testing.T) *Locales {
+	fsys := fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{
+			"payment.declined": "Your payment of {amount:%.2f} was declined",
+			"cart.items": {"one": "You have {count} item", "other": "You have {count} items"}
+		}`)},
+		"locales/fr.json": {Data: []byte(`{
+			"payment.declined": "Votre paiement de {amount:%.2f} a été refusé",
+			"cart.items": {"one": "Vous avez {count} article", "other": "Vous avez {count} articles"}
+		}`)},
+		"locales/ru.json": {Data: []byte(`{
+			"cart.items": {"one": "{count} товар", "few": "{count} товара", "many": "{count} товаров"}
+		}`)},
+	}
+
+	locales := NewLocales("en")
+	if err := locales.LoadFS(fsys, "locales"); err != nil {
+		t.Fatal(err)
+	}
+	locales.Add("pt-BR", map[string]string{"payment.declined": "Seu pagamento de {amount:%.2f} foi recusado"})
+	return locales
+}
+
+func TestLocalesTranslate(t *testing.T) {
+	locales := testLocales(t)
+
+	tests := []struct {
+		name   string
+		lang   string
+		id     string
+		params []any
+		want   string
+	}{
+		{"English", "en", "payment.declined", []any{"amount", 9.5}, "Your payment of 9.50 was declined"},
+		{"French", "fr", "payment.declined", []any{"amount", 9.5}, "Votre paiement de 9.50 a été refusé"},
+		{"Regional falls back to base", "fr-CA", "payment.declined", []any{"amount", 1.0}, "Votre paiement de 1.00 a été refusé"},
+		{"Exact regional match", "pt-BR", "payment.declined", []any{"amount", 1.0}, "Seu pagamento de 1.00 foi recusado"},
+		{"Missing language falls back to default", "de", "payment.declined", []any{"amount", 1.0}, "Your payment of 1.00 was declined"},
+		{"Missing message falls back to default", "ru", "payment.declined", []any{"amount", 1.0}, "Your payment of 1.00 was declined"},
+		{"English singular", "en", "cart.items", []any{"count", 1}, "You have 1 item"},
+		{"English plural", "en", "cart.items", []any{"count", 0}, "You have 0 items"},
+		{"French zero is singular", "fr", "cart.items", []any{"count", 0}, "Vous avez 0 article"},
+		{"Russian one", "ru", "cart.items", []any{"count", 21}, "21 товар"},
+		{"Russian few", "ru", "cart.items", []any{"count", 3}, "3 товара"},
+		{"Russian many", "ru", "cart.items", []any{"count", 11}, "11 товаров"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := locales.Translate(tt.lang, tt.id, tt.params...)
+			if !ok || got != tt.want {
+				t.Errorf("Expected %q, got %q (found: %v)", tt.want, got, ok)
+			}
+		})
+	}
+
+	if _, ok := locales.Translate("en", "no.such.message"); ok {
+		t.Error("Expected a missing message not to be found")
+	}
+
+	locales.SetFallback("gl", "pt-br")
+	if got, _ := locales.Translate("gl", "payment.declined", "amount", 2.0); got != "Seu pagamento de 2.00 foi recusado" {
+		t.Errorf("Expected the custom fallback to be used, got %q", got)
+	}
+}
+
+func TestUserMsgLocalized(t *testing.T) {
+	locales := testLocales(t)
+
+	se := WrapAsSErr(errors.New("card declined"))
+	se.SetUserMsgID("payment.declined", Severity.Error, "amount", 12.0)
+
+	if msg, sev := locales.UserMsg(se, "fr"); msg != "Votre paiement de 12.00 a été refusé" || sev != Severity.Error {
+		t.Errorf("Unexpected localized message %q with severity %q", msg, sev)
+	}
+
+	// Falls back to the literal message when the id is unknown
+	se2 := WrapAsSErr(errors.New("card declined"))
+	se2.SetUserMsg("Payment declined", Severity.Error)
+	se2.SetUserMsgID("payment.unknown", Severity.Error)
+	if msg, _ := locales.UserMsg(se2, "fr"); msg != "Payment declined" {
+		t.Errorf("Expected the literal user message, got %q", msg)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "de;q=0.9, fr-CH, en;q=0.8")
+	if msg, _ := locales.UserMsgForRequest(se, req); msg != "Votre paiement de 12.00 a été refusé" {
+		t.Errorf("Expected the negotiated French message, got %q", msg)
+	}
+
+	if msg, _ := UserMsgLocalized(errors.New("plain"), "en"); msg != "" {
+		t.Errorf("Expected no user message for a plain error, got %q", msg)
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	supported := []string{"en", "fr", "pt-BR"}
+
+	tests := map[string]string{
+		"":                        "en",
+		"fr":                      "fr",
+		"de, fr;q=0.5":            "fr",
+		"pt":                      "pt-BR",
+		"pt-br":                   "pt-BR",
+		"es;q=1, en;q=0.1":        "en",
+		"fr;q=0, *;q=0.5":         "en",
+		"en-US,en;q=0.9,fr;q=0.8": "en",
+	}
+	for header, want := range tests {
+		if got := NegotiateLanguage(header, supported...); got != want {
+			t.Errorf("NegotiateLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -83,3 +83,52 @@ func TestUserMsgFromErr(t *testing.T) {
 		})
 	}
 }
+
+func TestUserMsgPolicies(t *testing.T) {
+	inner := WrapAsSErr(errors.New("disk full"))
+	inner.SetUserMsg("Storage is unavailable", Severity.Error)
+
+	outer := WrapAsSErr(inner, "op", "save")
+	outer.SetUserMsg("Your document could not be saved", Severity.Warn)
+
+	msgs := outer.UserMessages()
+	if len(msgs) != 2 {
+		t.Fatalf("Expected 2 user messages, got %d", len(msgs))
+	}
+	if msgs[0].Layer != 0 || msgs[1].Layer != 1 {
+		t.Errorf("Expected messages at layers 0 and 1, got %d and %d", msgs[0].Layer, msgs[1].Layer)
+	}
+	if msgs[1].Function != "rohanthewiz/serr.TestUserMsgPolicies" || msgs[1].Location == "" {
+		t.Errorf("Expected the layer location and function to be set, got %q, %q", msgs[1].Location, msgs[1].Function)
+	}
+
+	tests := []struct {
+		policy  UserMsgPolicy
+		wantMsg string
+		wantSev string
+	}{
+		{UserMsgOutermostWins, "Your document could not be saved", Severity.Warn},
+		{UserMsgInnermostWins, "Storage is unavailable", Severity.Error},
+		{UserMsgHighestSeverityWins, "Storage is unavailable", Severity.Error},
+		{UserMsgCollectAll, "Your document could not be saved\nStorage is unavailable", Severity.Error},
+	}
+	for _, tt := range tests {
+		msg, sev := outer.resolveUserMsg(tt.policy)
+		if msg != tt.wantMsg || sev != tt.wantSev {
+			t.Errorf("Policy %d: expected (%q, %q), got (%q, %q)", tt.policy, tt.wantMsg, tt.wantSev, msg, sev)
+		}
+	}
+
+	if n := len(outer.ResolveUserMsgs(UserMsgCollectAll)); n != 2 {
+		t.Errorf("Expected CollectAll to keep 2 messages, got %d", n)
+	}
+
+	// The default policy no longer concatenates messages or severities
+	if msg, sev := UserMsg(outer); msg != "Your document could not be saved" || sev != Severity.Warn {
+		t.Errorf("Expected the outermost message by default, got (%q, %q)", msg, sev)
+	}
+
+	if len(WrapAsSErr(errors.New("no message")).UserMessages()) != 0 {
+		t.Error("Expected no user messages")
+	}
+}
@@ -0,0 +1,84 @@
+package serr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// FieldError is a single problem with an input field
+type FieldError struct {
+	// Path locates the field, as a JSON pointer (/items/0/qty) or dotted path (items.0.qty)
+	Path string `json:"path"`
+	// Rule is the validation rule that failed, e.g. "required" or "max"
+	Rule string `json:"rule"`
+	// UserMsg is a message that can be shown next to the field
+	UserMsg string         `json:"message,omitempty"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// ValidationErrors collects the field problems of an input, to be returned as a single error.
+// Its Err method returns an SErr of KindInvalid with HTTP status 422,
+// and the ValidationErrors can be recovered from the chain with errors.As
+// Example:
+//
+//	var ve serr.ValidationErrors
+//	if req.Email == "" {
+//		ve.Add("/email", "required", "Email is required")
+//	}
+//	if req.Qty > 10 {
+//		ve.Add("/qty", "max", "At most {max} items may be ordered", "max", 10)
+//	}
+//	return ve.Err() // nil if there are no problems
+type ValidationErrors struct {
+	Fields []FieldError
+}
+
+// Add records a problem with a field. Params are name, value pairs,
+// which may be referenced in the user message by name, e.g. "At most {max} items"
+func (ve *ValidationErrors) Add(path, rule, userMsg string, params ...any) {
+	fe := FieldError{Path: path, Rule: rule, UserMsg: expandNamed(userMsg, params)}
+	if len(params) > 1 {
+		fe.Params = map[string]any{}
+		for i := 0; i+1 < len(params); i += 2 {
+			if name, ok := params[i].(string); ok {
+				fe.Params[name] = params[i+1]
+			}
+		}
+	}
+	ve.Fields = append(ve.Fields, fe)
+}
+
+// Len returns the number of problems
+func (ve *ValidationErrors) Len() int {
+	return len(ve.Fields)
+}
+
+// Error renders all problems as a single message
+func (ve *ValidationErrors) Error() string {
+	problems := make([]string, 0, len(ve.Fields))
+	for _, fe := range ve.Fields {
+		problems = append(problems, fe.Path+": "+fe.Rule)
+	}
+	return "validation failed: " + strings.Join(problems, "; ")
+}
+
+// Err returns nil if there are no problems,
+// otherwise an SErr of KindInvalid with HTTP status 422 wrapping the ValidationErrors
+func (ve *ValidationErrors) Err() error {
+	if ve == nil || len(ve.Fields) == 0 {
+		return nil
+	}
+
+	se := SErr{err: ve, fields: []any{KindKey, KindInvalid, HTTPStatusKey, http.StatusUnprocessableEntity}}
+	return se.newSErr()
+}
+
+// ValidationErrorsFrom returns the ValidationErrors in err's chain, if any
+func ValidationErrorsFrom(err error) (*ValidationErrors, bool) {
+	var ve *ValidationErrors
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	return nil, false
+}
@@ -0,0 +1,41 @@
+package serr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	var ve ValidationErrors
+	if ve.Err() != nil {
+		t.Error("Expected no error without problems")
+	}
+
+	ve.Add("/email", "required", "Email is required")
+	ve.Add("/items/0/qty", "max", "At most {max} items may be ordered", "max", 10)
+
+	err := ve.Err()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if err.Error() != "validation failed: /email: required; /items/0/qty: max" {
+		t.Errorf("Unexpected error message '%s'", err.Error())
+	}
+	if _, ok := err.(SErr); !ok {
+		t.Error("Expected Err() to return an SErr")
+	}
+	if KindOf(err) != KindInvalid || HTTPStatusOf(err) != http.StatusUnprocessableEntity {
+		t.Errorf("Expected kind invalid and status 422, got %s and %d", KindOf(err), HTTPStatusOf(err))
+	}
+
+	wrapped := fmt.Errorf("create order: %w", Wrap(err, "user", "u1"))
+	var found *ValidationErrors
+	if !errors.As(wrapped, &found) || found.Len() != 2 {
+		t.Fatal("Expected to find the ValidationErrors with errors.As")
+	}
+	if fe := found.Fields[1]; fe.UserMsg != "At most 10 items may be ordered" || fe.Params["max"] != 10 {
+		t.Errorf("Unexpected field error %+v", fe)
+	}
+}